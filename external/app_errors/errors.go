@@ -0,0 +1,262 @@
+// Package app_errors contains the typed application errors mapped to the gRPC and HTTP status codes.
+package app_errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	"github.com/dysnix/predictkube-libs/external/cache"
+)
+
+type Kind uint8
+
+const (
+	Internal Kind = iota
+	NotFound
+	Conflict
+	Validation
+	Unauthenticated
+	PermissionDenied
+	RateLimited
+	Unavailable
+	Timeout
+	Canceled
+	NotImplemented
+)
+
+var kinds = map[Kind]struct {
+	name       string
+	code       codes.Code
+	httpStatus int
+}{
+	Internal:         {"internal", codes.Internal, http.StatusInternalServerError},
+	NotFound:         {"not_found", codes.NotFound, http.StatusNotFound},
+	Conflict:         {"conflict", codes.AlreadyExists, http.StatusConflict},
+	Validation:       {"validation", codes.InvalidArgument, http.StatusBadRequest},
+	Unauthenticated:  {"unauthenticated", codes.Unauthenticated, http.StatusUnauthorized},
+	PermissionDenied: {"permission_denied", codes.PermissionDenied, http.StatusForbidden},
+	RateLimited:      {"rate_limited", codes.ResourceExhausted, http.StatusTooManyRequests},
+	Unavailable:      {"unavailable", codes.Unavailable, http.StatusServiceUnavailable},
+	Timeout:          {"timeout", codes.DeadlineExceeded, http.StatusGatewayTimeout},
+	Canceled:         {"canceled", codes.Canceled, 499},
+	NotImplemented:   {"not_implemented", codes.Unimplemented, http.StatusNotImplemented},
+}
+
+func (k Kind) String() string {
+	if kind, ok := kinds[k]; ok {
+		return kind.name
+	}
+
+	return kinds[Internal].name
+}
+
+// Code returns the gRPC status code of the kind.
+func (k Kind) Code() codes.Code {
+	if kind, ok := kinds[k]; ok {
+		return kind.code
+	}
+
+	return codes.Internal
+}
+
+// HTTPStatus returns the HTTP status code of the kind.
+func (k Kind) HTTPStatus() int {
+	if kind, ok := kinds[k]; ok {
+		return kind.httpStatus
+	}
+
+	return http.StatusInternalServerError
+}
+
+// KindOfCode returns the kind of the gRPC status code.
+func KindOfCode(code codes.Code) Kind {
+	switch code {
+	case codes.FailedPrecondition, codes.OutOfRange:
+		return Validation
+	case codes.Aborted:
+		return Conflict
+	}
+
+	for k, kind := range kinds {
+		if kind.code == code {
+			return k
+		}
+	}
+
+	return Internal
+}
+
+// the sentinels are matched by the kind with errors.Is
+var (
+	ErrInternal         = &Error{Kind: Internal}
+	ErrNotFound         = &Error{Kind: NotFound}
+	ErrConflict         = &Error{Kind: Conflict}
+	ErrValidation       = &Error{Kind: Validation}
+	ErrUnauthenticated  = &Error{Kind: Unauthenticated}
+	ErrPermissionDenied = &Error{Kind: PermissionDenied}
+	ErrRateLimited      = &Error{Kind: RateLimited}
+	ErrUnavailable      = &Error{Kind: Unavailable}
+	ErrTimeout          = &Error{Kind: Timeout}
+	ErrCanceled         = &Error{Kind: Canceled}
+	ErrNotImplemented   = &Error{Kind: NotImplemented}
+)
+
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// Error is the application error, the reason and the metadata are the machine-readable
+// details of the error returned to the clients with the message.
+type Error struct {
+	Kind       Kind
+	Message    string
+	Reason     string
+	Metadata   map[string]string
+	Violations []FieldViolation
+	RetryAfter time.Duration
+	Err        error
+}
+
+func New(kind Kind, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap returns the error of the kind caused by err, the message of err is used when the format is empty.
+func Wrap(err error, kind Kind, format string, args ...interface{}) *Error {
+	e := New(kind, format, args...)
+	e.Err = err
+
+	if len(e.Message) == 0 && err != nil {
+		e.Message = err.Error()
+	}
+
+	return e
+}
+
+func NewNotFound(format string, args ...interface{}) *Error {
+	return New(NotFound, format, args...)
+}
+
+func NewConflict(format string, args ...interface{}) *Error {
+	return New(Conflict, format, args...)
+}
+
+func NewValidation(violations ...FieldViolation) *Error {
+	return &Error{Kind: Validation, Message: "invalid request", Violations: violations}
+}
+
+func NewUnauthenticated(format string, args ...interface{}) *Error {
+	return New(Unauthenticated, format, args...)
+}
+
+func NewPermissionDenied(format string, args ...interface{}) *Error {
+	return New(PermissionDenied, format, args...)
+}
+
+func NewRateLimited(retryAfter time.Duration, format string, args ...interface{}) *Error {
+	e := New(RateLimited, format, args...)
+	e.RetryAfter = retryAfter
+
+	return e
+}
+
+func NewUnavailable(format string, args ...interface{}) *Error {
+	return New(Unavailable, format, args...)
+}
+
+func NewInternal(format string, args ...interface{}) *Error {
+	return New(Internal, format, args...)
+}
+
+// WithReason sets the machine-readable reason of the error, e.g. "CLUSTER_NOT_FOUND".
+func (e *Error) WithReason(reason string, metadata map[string]string) *Error {
+	e.Reason = reason
+	e.Metadata = metadata
+
+	return e
+}
+
+func (e *Error) WithViolation(field, description string) *Error {
+	e.Violations = append(e.Violations, FieldViolation{Field: field, Description: description})
+	return e
+}
+
+func (e *Error) Error() string {
+	if len(e.Message) == 0 {
+		return e.Kind.String()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the errors of the same kind, so the sentinels work with errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && len(t.Message) == 0
+}
+
+// GRPCStatus makes the error convertible by the grpc status package.
+func (e *Error) GRPCStatus() *status.Status {
+	return toStatus(e)
+}
+
+// KindOf returns the kind of the error, the cache, gorm and context errors are mapped too.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	switch {
+	case errors.Is(err, cache.ErrNil), errors.Is(err, gorm.ErrRecordNotFound):
+		return NotFound
+	case errors.Is(err, gorm.ErrPrimaryKeyRequired), errors.Is(err, gorm.ErrModelValueRequired),
+		errors.Is(err, gorm.ErrInvalidData), errors.Is(err, gorm.ErrInvalidField),
+		errors.Is(err, gorm.ErrEmptySlice), errors.Is(err, gorm.ErrInvalidValue),
+		errors.Is(err, gorm.ErrInvalidValueOfLength), errors.Is(err, cache.ErrEmptyObject):
+		return Validation
+	case errors.Is(err, gorm.ErrNotImplemented):
+		return NotImplemented
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	}
+
+	if st, ok := status.FromError(err); ok && err != nil {
+		return KindOfCode(st.Code())
+	}
+
+	return Internal
+}
+
+// From returns the application error of err, the other errors are wrapped with their kind.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	return Wrap(err, KindOf(err), "")
+}
+
+// HTTPStatus returns the HTTP status code of the error.
+func HTTPStatus(err error) int {
+	return KindOf(err).HTTPStatus()
+}
@@ -0,0 +1,132 @@
+package app_errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	"github.com/dysnix/predictkube-libs/external/cache"
+)
+
+func TestKindOf(t *testing.T) {
+	var cases = []struct {
+		name       string
+		err        error
+		wantKind   Kind
+		wantCode   codes.Code
+		wantStatus int
+	}{
+		{
+			name:       "application error",
+			err:        NewConflict("cluster %s exists", "bsc-1"),
+			wantKind:   Conflict,
+			wantCode:   codes.AlreadyExists,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "wrapped application error",
+			err:        fmt.Errorf("create cluster: %w", NewRateLimited(time.Second, "slow down")),
+			wantKind:   RateLimited,
+			wantCode:   codes.ResourceExhausted,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "cache miss",
+			err:        fmt.Errorf("get settings: %w", cache.ErrNil),
+			wantKind:   NotFound,
+			wantCode:   codes.NotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "gorm record not found",
+			err:        gorm.ErrRecordNotFound,
+			wantKind:   NotFound,
+			wantCode:   codes.NotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "gorm invalid data",
+			err:        gorm.ErrInvalidData,
+			wantKind:   Validation,
+			wantCode:   codes.InvalidArgument,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "context deadline",
+			err:        context.DeadlineExceeded,
+			wantKind:   Timeout,
+			wantCode:   codes.DeadlineExceeded,
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "status error",
+			err:        status.Error(codes.Unauthenticated, "token expired"),
+			wantKind:   Unauthenticated,
+			wantCode:   codes.Unauthenticated,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unknown error",
+			err:        errors.New("boom"),
+			wantKind:   Internal,
+			wantCode:   codes.Internal,
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.wantKind, KindOf(c.err))
+			assert.Equal(t, c.wantCode, ToStatus(c.err).Code())
+			assert.Equal(t, c.wantStatus, HTTPStatus(c.err))
+		})
+	}
+}
+
+func TestStatusRoundTrip(t *testing.T) {
+	var cases = []struct {
+		name string
+		err  *Error
+	}{
+		{
+			name: "not found with reason",
+			err:  NewNotFound("cluster bsc-1 not found").WithReason("CLUSTER_NOT_FOUND", map[string]string{"cluster_id": "bsc-1"}),
+		},
+		{
+			name: "validation with violations",
+			err:  NewValidation(FieldViolation{Field: "header.cluster_id", Description: "is required"}),
+		},
+		{
+			name: "rate limited with retry delay",
+			err:  NewRateLimited(3*time.Second, "too many requests"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// the client gets the error through the status proto
+			st, ok := status.FromError(c.err)
+			require.True(t, ok)
+
+			out := FromStatus(status.ErrorProto(st.Proto()))
+			require.NotNil(t, out)
+
+			assert.Equal(t, c.err.Kind, out.Kind)
+			assert.Equal(t, c.err.Message, out.Message)
+			assert.Equal(t, c.err.Reason, out.Reason)
+			assert.Equal(t, c.err.Metadata, out.Metadata)
+			assert.Equal(t, c.err.Violations, out.Violations)
+			assert.Equal(t, c.err.RetryAfter, out.RetryAfter)
+			assert.True(t, errors.Is(out, &Error{Kind: c.err.Kind}))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package app_errors
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ToStatus converts the error to the gRPC status with the details of the application error,
+// the status errors are returned as is.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+	}
+
+	return toStatus(From(err))
+}
+
+func toStatus(e *Error) *status.Status {
+	st := status.New(e.Kind.Code(), e.Error())
+
+	var details []proto.Message
+
+	if len(e.Reason) > 0 {
+		details = append(details, &errdetails.ErrorInfo{Reason: e.Reason, Metadata: e.Metadata})
+	}
+
+	if len(e.Violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range e.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+
+		details = append(details, badRequest)
+	}
+
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)})
+	}
+
+	if len(details) == 0 {
+		return st
+	}
+
+	p := st.Proto()
+	for _, detail := range details {
+		packed, err := anypb.New(detail)
+		if err != nil {
+			return st
+		}
+
+		p.Details = append(p.Details, packed)
+	}
+
+	return status.FromProto(p)
+}
+
+// FromStatus converts the error returned by the gRPC client back to the application error,
+// the errors which are not the status errors are wrapped with their kind.
+func FromStatus(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return From(err)
+	}
+
+	e := &Error{Kind: KindOfCode(st.Code()), Message: st.Message(), Err: err}
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			e.Reason = d.GetReason()
+			e.Metadata = d.GetMetadata()
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				e.Violations = append(e.Violations, FieldViolation{Field: v.GetField(), Description: v.GetDescription()})
+			}
+		case *errdetails.RetryInfo:
+			e.RetryAfter = d.GetRetryDelay().AsDuration()
+		}
+	}
+
+	return e
+}
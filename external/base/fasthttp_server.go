@@ -21,6 +21,7 @@ import (
 	libs "github.com/dysnix/predictkube-libs/external/configs"
 	"github.com/dysnix/predictkube-libs/external/grpc/client"
 	libsSrv "github.com/dysnix/predictkube-libs/external/grpc/server"
+	"github.com/dysnix/predictkube-libs/external/tracing"
 	health "google.golang.org/grpc/health/grpc_health_v1"
 )
 
@@ -65,18 +66,17 @@ func NewFastHttpServer(options ...libs.Option) (out *FastHttpServer, err error)
 	if out.conf.GetGrpc() != nil {
 		if out.grpcConn == nil {
 			// grpc client for ping with some service
-			var grpcClientOpt []grpc.DialOption
-			if out.conf.GetClient() != nil {
-				grpcClientOpt, err = client.SetGrpcClientOptions(out.conf.GetGrpc(), out.conf.GetBase(), client.InjectClientMetadataInterceptor(*out.conf.GetClient()))
-			} else {
-				grpcClientOpt, err = client.SetGrpcClientOptions(out.conf.GetGrpc(), out.conf.GetBase())
+			var logger *zap.SugaredLogger
+			if out.logger != nil {
+				logger = &out.logger.SugaredLogger
 			}
 
-			if err != nil {
-				return nil, err
+			if clientConf := out.conf.GetClient(); clientConf != nil {
+				out.grpcConn, err = client.DialWithCredentials(out.conf.GetGrpc(), out.conf.GetBase(), *clientConf, logger)
+			} else {
+				out.grpcConn, err = client.DialWithLogger(out.conf.GetGrpc(), out.conf.GetBase(), logger)
 			}
 
-			out.grpcConn, err = grpc.Dial(fmt.Sprintf("%s:%d", out.conf.GetGrpc().Conn.Host, out.conf.GetGrpc().Conn.Port), grpcClientOpt...)
 			if err != nil {
 				return nil, err
 			}
@@ -103,6 +103,16 @@ func (s *FastHttpServer) SetGrpcClientConn(conn *grpc.ClientConn) {
 	s.grpcConn = conn
 }
 
+func (s *FastHttpServer) handler() fasthttp.RequestHandler {
+	handler := s.CorsMiddleware(s.routing().Handler)
+
+	if s.conf.GetBase().Tracing.Enabled {
+		handler = tracing.FastHttpMiddleware(handler)
+	}
+
+	return fasthttp.CompressHandler(s.PanicMiddleware(handler))
+}
+
 func (s *FastHttpServer) routing() *router.Router {
 	r := router.New()
 
@@ -141,7 +151,7 @@ func (s *FastHttpServer) init() {
 		WriteTimeout:       s.conf.GetBase().Single.HTTPTransport.WriteTimeout,
 		IdleTimeout:        s.conf.GetBase().Single.HTTPTransport.MaxIdleConnDuration,
 		Logger:             s.logger,
-		Handler:            fasthttp.CompressHandler(s.PanicMiddleware(s.CorsMiddleware(s.routing().Handler))),
+		Handler:            s.handler(),
 	}
 
 	if s.conf.GetBase().IsDebugMode {
@@ -0,0 +1,124 @@
+package base
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/dysnix/predictkube-libs/external/app_errors"
+	libs "github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/fault"
+)
+
+const (
+	DefaultFaultAdminPath = "/admin/faults"
+
+	bearerPrefix = "Bearer "
+)
+
+var (
+	ErrFaultAdminToken = errors.New("fault admin endpoint requires the admin token")
+)
+
+// FaultState is the runtime state of the fault injector in the admin endpoint,
+// the omitted fields are left as is by the updates.
+type FaultState struct {
+	Active *bool             `json:"active,omitempty"`
+	Rules  *[]libs.FaultRule `json:"rules,omitempty"`
+}
+
+// FaultAdminRoutes returns the admin routes of the shared fault injector of the configs for SetRoutes,
+// GET returns the state of the injector and PUT updates it, e.g. {"active": false} stops the injection at runtime.
+// The requests are authenticated by the "Authorization: Bearer" header with the admin token of the configs,
+// ErrFaultAdminToken is returned when the token isn't set.
+func (s *HttpServer) FaultAdminRoutes(conf *libs.FaultInjection, path string) (map[string]*libs.Route, error) {
+	token, err := faultAdminToken(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	injector, err := fault.For(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(path) == 0 {
+		path = DefaultFaultAdminPath
+	}
+
+	return map[string]*libs.Route{
+		path: {
+			RequestHandler: func(ctx *fasthttp.RequestCtx) {
+				if !validBearer(ctx, token) {
+					s.ErrorPrint(ctx, app_errors.NewUnauthenticated("invalid admin token"), 0)
+					ctx.Response.Header.Set(fasthttp.HeaderWWWAuthenticate, strings.TrimSpace(bearerPrefix))
+					return
+				}
+
+				switch string(ctx.Method()) {
+				case fasthttp.MethodGet:
+				case fasthttp.MethodPut, fasthttp.MethodPost:
+					var state FaultState
+					if err := json.Unmarshal(ctx.PostBody(), &state); err != nil {
+						s.ErrorPrint(ctx, app_errors.NewValidation().WithViolation("body", err.Error()), 0)
+						return
+					}
+
+					if state.Rules != nil {
+						if err := injector.SetRules(*state.Rules); err != nil {
+							s.ErrorPrint(ctx, app_errors.NewValidation().WithViolation("rules", err.Error()), 0)
+							return
+						}
+					}
+
+					if state.Active != nil {
+						injector.SetActive(*state.Active)
+					}
+
+					s.logger.Infof("fault injection is updated: active %v", injector.Active())
+				default:
+					s.ErrorPrint(ctx, app_errors.New(app_errors.Validation, "method %s is not allowed", ctx.Method()), fasthttp.StatusMethodNotAllowed)
+					ctx.Response.Header.Set(fasthttp.HeaderAllow, "GET, PUT, POST")
+					return
+				}
+
+				active, rules := injector.Active(), injector.Rules()
+				s.JsonResp(ctx, FaultState{Active: &active, Rules: &rules})
+			},
+		},
+	}, nil
+}
+
+func faultAdminToken(conf *libs.FaultInjection) ([]byte, error) {
+	token := []byte(conf.AdminToken)
+
+	if len(token) == 0 && len(conf.AdminTokenFile) > 0 {
+		data, err := os.ReadFile(conf.AdminTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("fault admin token: %w", err)
+		}
+
+		token = bytes.TrimSpace(data)
+	}
+
+	if len(token) == 0 {
+		return nil, ErrFaultAdminToken
+	}
+
+	return token, nil
+}
+
+func validBearer(ctx *fasthttp.RequestCtx, token []byte) bool {
+	value := ctx.Request.Header.Peek(fasthttp.HeaderAuthorization)
+	if len(value) <= len(bearerPrefix) || string(value[:len(bearerPrefix)]) != bearerPrefix {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(value[len(bearerPrefix):], token) == 1
+}
@@ -0,0 +1,108 @@
+package base
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+
+	libs "github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/fault"
+)
+
+type serverConfigs struct {
+	conf *libs.Single
+}
+
+func (c serverConfigs) GetServerConfigs() *libs.Single {
+	return c.conf
+}
+
+func TestFaultAdminRoutes(t *testing.T) {
+	srv, err := NewHttpServer(
+		libs.SetServerConfigs(serverConfigs{conf: &libs.Single{
+			Buffer:       &libs.Buffer{},
+			TCPKeepalive: &libs.TCPKeepalive{},
+		}}),
+		libs.SetServerLogger(zap.NewNop().Sugar()),
+	)
+	require.NoError(t, err)
+
+	_, err = srv.FaultAdminRoutes(&libs.FaultInjection{Enabled: true}, "")
+	assert.ErrorIs(t, err, ErrFaultAdminToken)
+
+	tokenFile := filepath.Join(t.TempDir(), "admin-token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("admin-token\n"), 0600))
+
+	conf := &libs.FaultInjection{Enabled: true, AdminTokenFile: tokenFile}
+
+	routes, err := srv.FaultAdminRoutes(conf, "")
+	require.NoError(t, err)
+	require.Contains(t, routes, DefaultFaultAdminPath)
+
+	var cases = []struct {
+		name          string
+		authorization string
+		body          string
+		wantStatus    int
+		wantActive    bool
+	}{
+		{
+			name:       "missing token",
+			body:       `{"active":true}`,
+			wantStatus: fasthttp.StatusUnauthorized,
+		},
+		{
+			name:          "wrong token",
+			authorization: "Bearer other-token",
+			body:          `{"active":true}`,
+			wantStatus:    fasthttp.StatusUnauthorized,
+		},
+		{
+			name:          "not bearer token",
+			authorization: "admin-token",
+			body:          `{"active":true}`,
+			wantStatus:    fasthttp.StatusUnauthorized,
+		},
+		{
+			name:          "valid token",
+			authorization: "Bearer admin-token",
+			body:          `{"active":true}`,
+			wantStatus:    fasthttp.StatusOK,
+			wantActive:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(fasthttp.MethodPut)
+			ctx.Request.SetBodyString(c.body)
+
+			if len(c.authorization) > 0 {
+				ctx.Request.Header.Set(fasthttp.HeaderAuthorization, c.authorization)
+			}
+
+			routes[DefaultFaultAdminPath].RequestHandler(ctx)
+
+			assert.Equal(t, c.wantStatus, ctx.Response.StatusCode())
+
+			if c.wantStatus == fasthttp.StatusOK {
+				var state FaultState
+				require.NoError(t, json.Unmarshal(ctx.Response.Body(), &state))
+				require.NotNil(t, state.Active)
+				assert.Equal(t, c.wantActive, *state.Active)
+			}
+		})
+	}
+
+	// the other configs of the same name, e.g. the HTTP transport ones, are toggled by the endpoint too
+	other, err := fault.For(&libs.FaultInjection{Enabled: true})
+	require.NoError(t, err)
+	assert.True(t, other.Active())
+}
@@ -2,13 +2,17 @@ package base
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 
 	"github.com/fasthttp/router"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 
+	"github.com/dysnix/predictkube-libs/external/app_errors"
 	libs "github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/tracing"
 )
 
 const (
@@ -24,6 +28,8 @@ type HttpServer struct {
 	router  *router.Router
 	handler fasthttp.RequestHandler
 
+	middlewares []func(fasthttp.RequestHandler) fasthttp.RequestHandler
+
 	createConn bool
 }
 
@@ -57,14 +63,26 @@ func (s *HttpServer) SetLogger(lg *zap.SugaredLogger) {
 }
 
 func (s *HttpServer) SetMiddlewares(middlewares []func(fasthttp.RequestHandler) fasthttp.RequestHandler) {
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
+// buildHandler wraps the router with the base and the custom middlewares, it's called after
+// all the options are applied because the middlewares depend on the configs.
+func (s *HttpServer) buildHandler() {
 	baseMiddlewares := []func(fasthttp.RequestHandler) fasthttp.RequestHandler{
 		fasthttp.CompressHandler,
 		s.panicMiddleware,
-		s.corsMiddleware,
 	}
 
-	if len(middlewares) > 0 {
-		baseMiddlewares = append(baseMiddlewares, middlewares...)
+	if base, ok := s.conf.(libs.BaseGetter); ok && base.GetBase() != nil && base.GetBase().Tracing.Enabled {
+		baseMiddlewares = append(baseMiddlewares, tracing.FastHttpMiddleware)
+	}
+
+	baseMiddlewares = append(baseMiddlewares, s.corsMiddleware)
+	baseMiddlewares = append(baseMiddlewares, s.middlewares...)
+
+	if s.router == nil {
+		s.router = router.New()
 	}
 
 	s.handler = s.router.Handler
@@ -137,7 +155,11 @@ func (s *HttpServer) SetRoutes(routes map[string]map[string]*libs.Route) {
 					r.TRACE(path, route.RequestHandler)
 				}
 			default:
-				newGroup.ANY(path, route.RequestHandler)
+				if group != Root {
+					newGroup.ANY(path, route.RequestHandler)
+				} else {
+					r.ANY(path, route.RequestHandler)
+				}
 			}
 		}
 	}
@@ -145,7 +167,14 @@ func (s *HttpServer) SetRoutes(routes map[string]map[string]*libs.Route) {
 	s.router = r
 }
 
+// Handler returns the request handler of the routes wrapped with the middlewares.
+func (s *HttpServer) Handler() fasthttp.RequestHandler {
+	return s.handler
+}
+
 func (s *HttpServer) init() {
+	s.buildHandler()
+
 	s.server = &fasthttp.Server{
 		Name:               s.conf.GetServerConfigs().Name,
 		Concurrency:        int(s.conf.GetServerConfigs().Concurrency),
@@ -214,7 +243,13 @@ func (s *HttpServer) JsonResp(ctx *fasthttp.RequestCtx, resp interface{}) {
 	}
 }
 
+// ErrorPrint writes the JSON error response, the zero status code is taken from the kind of the error
+// (see app_errors.KindOf) and the details of the application errors are added to the response.
 func (s *HttpServer) ErrorPrint(ctx *fasthttp.RequestCtx, err error, statusCode int) {
+	if statusCode == 0 {
+		statusCode = app_errors.HTTPStatus(err)
+	}
+
 	ctx.Response.Reset()
 	ctx.SetStatusCode(statusCode)
 	ctx.SetContentTypeBytes([]byte("application/json"))
@@ -222,6 +257,27 @@ func (s *HttpServer) ErrorPrint(ctx *fasthttp.RequestCtx, err error, statusCode
 	resp := make(map[string]interface{})
 	if err != nil {
 		resp["error"] = err.Error()
+
+		var appErr *app_errors.Error
+		if errors.As(err, &appErr) {
+			resp["kind"] = appErr.Kind.String()
+
+			if len(appErr.Reason) > 0 {
+				resp["reason"] = appErr.Reason
+			}
+
+			if len(appErr.Metadata) > 0 {
+				resp["metadata"] = appErr.Metadata
+			}
+
+			if len(appErr.Violations) > 0 {
+				resp["violations"] = appErr.Violations
+			}
+
+			if appErr.RetryAfter > 0 {
+				ctx.Response.Header.Set(fasthttp.HeaderRetryAfter, fmt.Sprint(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+			}
+		}
 	} else {
 		resp["error"] = undefinedErr
 	}
@@ -0,0 +1,272 @@
+// Package breaker implements the circuit breakers of the outbound gRPC and HTTP calls.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+const (
+	DefaultWindow           = 10 * time.Second
+	DefaultMinRequests      = 20
+	DefaultFailureRate      = 0.5
+	DefaultOpenTimeout      = 30 * time.Second
+	DefaultHalfOpenRequests = 5
+	DefaultHalfOpenTimeout  = 30 * time.Second
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+var states = []State{Closed, Open, HalfOpen}
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// counts are the outcomes of the calls in the current window of the closed state
+// or since the breaker is half-open.
+type counts struct {
+	requests uint
+	failures uint
+	slow     uint
+}
+
+// Breaker trips open when the failure rate or the slow call rate of the calls in the window
+// exceeds the thresholds, rejects the calls for the open timeout and then lets the limited
+// number of probe calls through, the breaker is closed again when all of them succeed
+// and opened again when any of them fails or they don't finish in the half-open timeout.
+type Breaker struct {
+	name string
+	conf configs.CircuitBreaker
+
+	mu       sync.Mutex
+	state    State
+	counts   counts
+	expiry   time.Time
+	inFlight uint
+	// generation is changed with every state change to drop the outcomes of the older calls
+	generation uint64
+	now        func() time.Time
+}
+
+// New returns the closed breaker, the zero values of the config are replaced by the defaults.
+func New(name string, conf *configs.CircuitBreaker) *Breaker {
+	b := &Breaker{name: name, now: time.Now}
+	if conf != nil {
+		b.conf = *conf
+	}
+
+	if b.conf.Window <= 0 {
+		b.conf.Window = DefaultWindow
+	}
+
+	if b.conf.MinRequests == 0 {
+		b.conf.MinRequests = DefaultMinRequests
+	}
+
+	if b.conf.FailureRate <= 0 {
+		b.conf.FailureRate = DefaultFailureRate
+	}
+
+	if b.conf.OpenTimeout <= 0 {
+		b.conf.OpenTimeout = DefaultOpenTimeout
+	}
+
+	if b.conf.HalfOpenRequests == 0 {
+		b.conf.HalfOpenRequests = DefaultHalfOpenRequests
+	}
+
+	if b.conf.HalfOpenTimeout <= 0 {
+		b.conf.HalfOpenTimeout = DefaultHalfOpenTimeout
+	}
+
+	registerMetrics()
+	b.setState(Closed, b.now())
+
+	return b
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh(b.now())
+
+	return b.state
+}
+
+// Allow returns ErrOpen when the call is rejected, otherwise the done callback must be called
+// with the outcome and the duration of the call.
+func (b *Breaker) Allow() (done func(success bool, d time.Duration), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.refresh(now)
+
+	switch b.state {
+	case Open:
+		rejectedTotal.WithLabelValues(b.name).Inc()
+		return nil, ErrOpen
+	case HalfOpen:
+		if b.inFlight+b.counts.requests >= b.conf.HalfOpenRequests {
+			rejectedTotal.WithLabelValues(b.name).Inc()
+			return nil, ErrOpen
+		}
+
+		b.expiry = now.Add(b.conf.HalfOpenTimeout)
+	}
+
+	b.inFlight++
+	generation := b.generation
+
+	var once sync.Once
+
+	return func(success bool, d time.Duration) {
+		once.Do(func() {
+			b.done(generation, success, d)
+		})
+	}, nil
+}
+
+func (b *Breaker) done(generation uint64, success bool, d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.refresh(now)
+
+	// the outcomes of the calls started before the state change are dropped
+	if generation != b.generation {
+		return
+	}
+
+	b.inFlight--
+
+	slow := b.conf.SlowCallDuration > 0 && d >= b.conf.SlowCallDuration
+
+	b.counts.requests++
+	if !success {
+		b.counts.failures++
+	}
+
+	if slow {
+		b.counts.slow++
+	}
+
+	switch b.state {
+	case Closed:
+		if b.counts.requests >= b.conf.MinRequests && b.exceeded() {
+			b.setState(Open, now)
+		}
+	case HalfOpen:
+		if !success || slow {
+			b.setState(Open, now)
+		} else if b.counts.requests >= b.conf.HalfOpenRequests {
+			b.setState(Closed, now)
+		}
+	}
+}
+
+func (b *Breaker) exceeded() bool {
+	requests := float64(b.counts.requests)
+
+	if float64(b.counts.failures)/requests >= b.conf.FailureRate {
+		return true
+	}
+
+	return b.conf.SlowCallRate > 0 && float64(b.counts.slow)/requests >= b.conf.SlowCallRate
+}
+
+// refresh moves the open breaker to half-open after the timeout, opens the half-open breaker again
+// when the probes didn't finish in time and starts the new window of the closed breaker when the current one is over.
+func (b *Breaker) refresh(now time.Time) {
+	if now.Before(b.expiry) {
+		return
+	}
+
+	switch b.state {
+	case Open:
+		b.setState(HalfOpen, now)
+		return
+	case HalfOpen:
+		// the lost probes, e.g. the panicked calls, are counted as the failures
+		if b.inFlight > 0 {
+			b.setState(Open, now)
+		}
+
+		return
+	}
+
+	b.counts = counts{}
+	b.expiry = now.Add(b.conf.Window)
+}
+
+func (b *Breaker) setState(state State, now time.Time) {
+	b.state = state
+	b.counts = counts{}
+	b.inFlight = 0
+	b.generation++
+
+	switch state {
+	case Closed:
+		b.expiry = now.Add(b.conf.Window)
+	case Open:
+		b.expiry = now.Add(b.conf.OpenTimeout)
+	default:
+		// the half-open timeout is started by the probe calls
+		b.expiry = time.Time{}
+	}
+
+	for _, s := range states {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+
+		stateGauge.WithLabelValues(b.name, s.String()).Set(value)
+	}
+}
+
+// Group holds the breakers of the outbound targets created with the same config.
+type Group struct {
+	conf     *configs.CircuitBreaker
+	breakers sync.Map
+}
+
+func NewGroup(conf *configs.CircuitBreaker) *Group {
+	return &Group{conf: conf}
+}
+
+// Get returns the breaker of the target, it is created on the first use.
+func (g *Group) Get(name string) *Breaker {
+	if b, ok := g.breakers.Load(name); ok {
+		return b.(*Breaker)
+	}
+
+	b, _ := g.breakers.LoadOrStore(name, New(name, g.conf))
+
+	return b.(*Breaker)
+}
@@ -0,0 +1,177 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+type call struct {
+	success bool
+	d       time.Duration
+}
+
+func TestBreaker(t *testing.T) {
+	conf := &configs.CircuitBreaker{
+		Window:           time.Minute,
+		MinRequests:      4,
+		FailureRate:      0.5,
+		SlowCallDuration: time.Second,
+		SlowCallRate:     0.75,
+		OpenTimeout:      10 * time.Second,
+		HalfOpenRequests: 2,
+	}
+
+	var (
+		ok   = call{success: true, d: time.Millisecond}
+		fail = call{d: time.Millisecond}
+		slow = call{success: true, d: 2 * time.Second}
+	)
+
+	var cases = []struct {
+		name      string
+		calls     []call
+		wait      time.Duration
+		probes    []call
+		wantState State
+	}{
+		{
+			name:      "stays closed below the failure rate",
+			calls:     []call{ok, ok, ok, fail},
+			wantState: Closed,
+		},
+		{
+			name:      "stays closed below the minimum requests",
+			calls:     []call{fail, fail, fail},
+			wantState: Closed,
+		},
+		{
+			name:      "opens on the failure rate",
+			calls:     []call{ok, fail, ok, fail},
+			wantState: Open,
+		},
+		{
+			name:      "opens on the slow call rate",
+			calls:     []call{slow, slow, ok, slow},
+			wantState: Open,
+		},
+		{
+			name:      "new window resets the counts",
+			calls:     []call{fail, fail, fail},
+			wait:      time.Minute + time.Second,
+			probes:    []call{fail},
+			wantState: Closed,
+		},
+		{
+			name:      "half-open after the open timeout",
+			calls:     []call{fail, fail, fail, fail},
+			wait:      10 * time.Second,
+			wantState: HalfOpen,
+		},
+		{
+			name:      "closes after the successful probes",
+			calls:     []call{fail, fail, fail, fail},
+			wait:      10 * time.Second,
+			probes:    []call{ok, ok},
+			wantState: Closed,
+		},
+		{
+			name:      "opens again on the failed probe",
+			calls:     []call{fail, fail, fail, fail},
+			wait:      10 * time.Second,
+			probes:    []call{ok, fail},
+			wantState: Open,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			now := time.Now()
+
+			b := New(t.Name(), conf)
+			b.now = func() time.Time { return now }
+
+			for _, cl := range c.calls {
+				done, err := b.Allow()
+				require.NoError(t, err)
+				done(cl.success, cl.d)
+			}
+
+			now = now.Add(c.wait)
+
+			for _, cl := range c.probes {
+				done, err := b.Allow()
+				require.NoError(t, err)
+				done(cl.success, cl.d)
+			}
+
+			assert.Equal(t, c.wantState, b.State())
+
+			_, err := b.Allow()
+			if c.wantState == Open {
+				assert.ErrorIs(t, err, ErrOpen)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBreakerHalfOpenLimit(t *testing.T) {
+	now := time.Now()
+
+	b := New(t.Name(), &configs.CircuitBreaker{MinRequests: 1, OpenTimeout: time.Second, HalfOpenRequests: 2})
+	b.now = func() time.Time { return now }
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(false, 0)
+	require.Equal(t, Open, b.State())
+
+	now = now.Add(time.Second)
+
+	for i := 0; i < 2; i++ {
+		_, err = b.Allow()
+		require.NoError(t, err)
+	}
+
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+}
+
+func TestBreakerHalfOpenTimeout(t *testing.T) {
+	now := time.Now()
+
+	b := New(t.Name(), &configs.CircuitBreaker{MinRequests: 1, OpenTimeout: time.Second, HalfOpenRequests: 1, HalfOpenTimeout: 5 * time.Second})
+	b.now = func() time.Time { return now }
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(false, 0)
+
+	// the idle half-open breaker waits for the probes
+	now = now.Add(time.Minute)
+	require.Equal(t, HalfOpen, b.State())
+
+	// the probe is lost, e.g. the call panicked before done
+	_, err = b.Allow()
+	require.NoError(t, err)
+
+	now = now.Add(4 * time.Second)
+	require.Equal(t, HalfOpen, b.State())
+
+	now = now.Add(time.Second)
+	require.Equal(t, Open, b.State())
+
+	now = now.Add(time.Second)
+
+	done, err = b.Allow()
+	require.NoError(t, err)
+	done(true, 0)
+
+	assert.Equal(t, Closed, b.State())
+}
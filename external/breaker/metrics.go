@@ -0,0 +1,30 @@
+package breaker
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	stateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "circuit_breaker_state",
+		Help: "Current state of the circuit breaker by name, the gauge of the active state is 1.",
+	}, []string{"name", "state"})
+
+	rejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_rejected_total",
+		Help: "Total number of calls rejected by the open circuit breaker.",
+	}, []string{"name"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(
+			stateGauge,
+			rejectedTotal,
+		)
+	})
+}
@@ -0,0 +1,79 @@
+package configs
+
+// AuthzPolicy is the method-level access control policy of the server, the calls are denied unless a rule
+// allows them. The policy is usually kept in its own file read by ReadConfigFile, e.g.
+//
+//	dryRun: true
+//	rules:
+//	  - name: health
+//	    methods: ["grpc.health.v1.Health"]
+//	  - name: agents
+//	    roles: ["agent"]
+//	    methods: ["services.GatewaySaverService/SendMetrics"]
+//	  - name: public-api
+//	    scopes: ["metrics:read"]
+//	    claims:
+//	      tier: pro
+//	    methods: ["services.GatewaySaverService/GetMetricsOffset"]
+//
+// The dry-run policy only logs the denials and lets the calls through.
+type AuthzPolicy struct {
+	DryRun bool        `yaml:"dryRun" json:"dry_run"`
+	Rules  []AuthzRule `yaml:"rules" json:"rules" validate:"dive"`
+}
+
+// AuthzRule allows the calls of the Methods ("*" for all of them) to the principals having any of the Roles,
+// any of the Scopes of the API keys and all the Claims, the rule without any of them allows the calls to everyone.
+// The Methods items can be "/package.Service/Method", "package.Service/Method" or "package.Service".
+type AuthzRule struct {
+	Name    string            `yaml:"name" json:"name"`
+	Roles   []string          `yaml:"roles,omitempty" json:"roles,omitempty"`
+	Scopes  []string          `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+	Claims  map[string]string `yaml:"claims,omitempty" json:"claims,omitempty"`
+	Methods []string          `yaml:"methods" json:"methods" validate:"required,min=1"`
+}
+
+// MatchesMethod reports whether the rule covers the full gRPC method name.
+func (r *AuthzRule) MatchesMethod(fullMethod string) bool {
+	for _, method := range r.Methods {
+		if method == "*" {
+			return true
+		}
+
+		for _, key := range GrpcMethodKeys(fullMethod) {
+			if method == key {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Authz enables the authorization of the server calls by the policy of the PolicyPath file, the principals
+// of the calls are authenticated by the JWT tokens and the API keys.
+type Authz struct {
+	Enabled    bool         `yaml:"enabled" json:"enabled"`
+	PolicyPath string       `yaml:"policyPath" json:"policy_path" validate:"required_if=Enabled true,omitempty,file"`
+	JWT        *JWTAuth     `yaml:"jwt,omitempty" json:"jwt,omitempty"`
+	APIKeys    []APIKeyAuth `yaml:"apiKeys,omitempty" json:"api_keys,omitempty" validate:"omitempty,dive"`
+}
+
+// JWTAuth verifies the JWT tokens by the HMAC secret (HS256) of the SecretFile or the public key (RS256, ES256)
+// of the PEM PublicKeyFile, the Issuer and the Audience are checked when they are set. The roles of the principal
+// are the RolesClaim ("roles" by default) and its scopes are the space-delimited ScopesClaim ("scope" by default).
+type JWTAuth struct {
+	SecretFile    string `yaml:"secretFile,omitempty" json:"secret_file,omitempty" validate:"required_without=PublicKeyFile,omitempty,file"`
+	PublicKeyFile string `yaml:"publicKeyFile,omitempty" json:"public_key_file,omitempty" validate:"required_without=SecretFile,omitempty,file"`
+	Issuer        string `yaml:"issuer,omitempty" json:"issuer,omitempty"`
+	Audience      string `yaml:"audience,omitempty" json:"audience,omitempty"`
+	RolesClaim    string `yaml:"rolesClaim,omitempty" json:"roles_claim,omitempty"`
+	ScopesClaim   string `yaml:"scopesClaim,omitempty" json:"scopes_claim,omitempty"`
+}
+
+// APIKeyAuth grants the Scopes to the API key, the key is kept as the hex SHA-256 digest only.
+type APIKeyAuth struct {
+	Name   string   `yaml:"name" json:"name"`
+	SHA256 string   `yaml:"sha256" json:"sha256" validate:"required,len=64,hexadecimal"`
+	Scopes []string `yaml:"scopes" json:"scopes"`
+}
@@ -0,0 +1,200 @@
+package configs
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/xhit/go-str2duration/v2"
+)
+
+// CircuitBreaker configures the breakers of the outbound calls, the zero values are replaced
+// by the defaults of the breaker package.
+type CircuitBreaker struct {
+	Enabled          bool          `yaml:"enabled" json:"enabled"`
+	Window           time.Duration `yaml:"window" json:"window" validate:"gte=0"`
+	MinRequests      uint          `yaml:"minRequests" json:"min_requests"`
+	FailureRate      float64       `yaml:"failureRate" json:"failure_rate" validate:"gte=0,lte=1"`
+	SlowCallDuration time.Duration `yaml:"slowCallDuration" json:"slow_call_duration" validate:"gte=0"`
+	SlowCallRate     float64       `yaml:"slowCallRate" json:"slow_call_rate" validate:"gte=0,lte=1"`
+	OpenTimeout      time.Duration `yaml:"openTimeout" json:"open_timeout" validate:"gte=0"`
+	HalfOpenRequests uint          `yaml:"halfOpenRequests" json:"half_open_requests"`
+	HalfOpenTimeout  time.Duration `yaml:"halfOpenTimeout" json:"half_open_timeout" validate:"gte=0"`
+}
+
+func (cb *CircuitBreaker) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		Enabled          bool    `yaml:"enabled" json:"enabled"`
+		Window           string  `yaml:"window" json:"window"`
+		MinRequests      uint    `yaml:"minRequests" json:"min_requests"`
+		FailureRate      float64 `yaml:"failureRate" json:"failure_rate"`
+		SlowCallDuration string  `yaml:"slowCallDuration" json:"slow_call_duration"`
+		SlowCallRate     float64 `yaml:"slowCallRate" json:"slow_call_rate"`
+		OpenTimeout      string  `yaml:"openTimeout" json:"open_timeout"`
+		HalfOpenRequests uint    `yaml:"halfOpenRequests" json:"half_open_requests"`
+		HalfOpenTimeout  string  `yaml:"halfOpenTimeout" json:"half_open_timeout"`
+	}
+
+	if cb == nil {
+		*cb = CircuitBreaker{}
+	}
+
+	return json.Marshal(alias{
+		Enabled:          cb.Enabled,
+		Window:           HumanDuration(cb.Window),
+		MinRequests:      cb.MinRequests,
+		FailureRate:      cb.FailureRate,
+		SlowCallDuration: HumanDuration(cb.SlowCallDuration),
+		SlowCallRate:     cb.SlowCallRate,
+		OpenTimeout:      HumanDuration(cb.OpenTimeout),
+		HalfOpenRequests: cb.HalfOpenRequests,
+		HalfOpenTimeout:  HumanDuration(cb.HalfOpenTimeout),
+	})
+}
+
+func (cb *CircuitBreaker) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		Enabled          bool    `yaml:"enabled" json:"enabled"`
+		Window           string  `yaml:"window" json:"window"`
+		MinRequests      uint    `yaml:"minRequests" json:"min_requests"`
+		FailureRate      float64 `yaml:"failureRate" json:"failure_rate"`
+		SlowCallDuration string  `yaml:"slowCallDuration" json:"slow_call_duration"`
+		SlowCallRate     float64 `yaml:"slowCallRate" json:"slow_call_rate"`
+		OpenTimeout      string  `yaml:"openTimeout" json:"open_timeout"`
+		HalfOpenRequests uint    `yaml:"halfOpenRequests" json:"half_open_requests"`
+		HalfOpenTimeout  string  `yaml:"halfOpenTimeout" json:"half_open_timeout"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if cb == nil {
+		*cb = CircuitBreaker{}
+	}
+
+	cb.Enabled = tmp.Enabled
+	cb.MinRequests = tmp.MinRequests
+	cb.FailureRate = tmp.FailureRate
+	cb.SlowCallRate = tmp.SlowCallRate
+	cb.HalfOpenRequests = tmp.HalfOpenRequests
+
+	if len(tmp.Window) > 0 {
+		cb.Window, err = str2duration.ParseDuration(tmp.Window)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.SlowCallDuration) > 0 {
+		cb.SlowCallDuration, err = str2duration.ParseDuration(tmp.SlowCallDuration)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.OpenTimeout) > 0 {
+		cb.OpenTimeout, err = str2duration.ParseDuration(tmp.OpenTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.HalfOpenTimeout) > 0 {
+		cb.HalfOpenTimeout, err = str2duration.ParseDuration(tmp.HalfOpenTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cb *CircuitBreaker) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		Enabled          bool    `yaml:"enabled" json:"enabled"`
+		Window           string  `yaml:"window" json:"window"`
+		MinRequests      uint    `yaml:"minRequests" json:"min_requests"`
+		FailureRate      float64 `yaml:"failureRate" json:"failure_rate"`
+		SlowCallDuration string  `yaml:"slowCallDuration" json:"slow_call_duration"`
+		SlowCallRate     float64 `yaml:"slowCallRate" json:"slow_call_rate"`
+		OpenTimeout      string  `yaml:"openTimeout" json:"open_timeout"`
+		HalfOpenRequests uint    `yaml:"halfOpenRequests" json:"half_open_requests"`
+		HalfOpenTimeout  string  `yaml:"halfOpenTimeout" json:"half_open_timeout"`
+	}
+
+	if cb == nil {
+		*cb = CircuitBreaker{}
+	}
+
+	return alias{
+		Enabled:          cb.Enabled,
+		Window:           HumanDuration(cb.Window),
+		MinRequests:      cb.MinRequests,
+		FailureRate:      cb.FailureRate,
+		SlowCallDuration: HumanDuration(cb.SlowCallDuration),
+		SlowCallRate:     cb.SlowCallRate,
+		OpenTimeout:      HumanDuration(cb.OpenTimeout),
+		HalfOpenRequests: cb.HalfOpenRequests,
+		HalfOpenTimeout:  HumanDuration(cb.HalfOpenTimeout),
+	}, nil
+}
+
+func (cb *CircuitBreaker) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	type alias struct {
+		Enabled          bool    `yaml:"enabled" json:"enabled"`
+		Window           string  `yaml:"window" json:"window"`
+		MinRequests      uint    `yaml:"minRequests" json:"min_requests"`
+		FailureRate      float64 `yaml:"failureRate" json:"failure_rate"`
+		SlowCallDuration string  `yaml:"slowCallDuration" json:"slow_call_duration"`
+		SlowCallRate     float64 `yaml:"slowCallRate" json:"slow_call_rate"`
+		OpenTimeout      string  `yaml:"openTimeout" json:"open_timeout"`
+		HalfOpenRequests uint    `yaml:"halfOpenRequests" json:"half_open_requests"`
+		HalfOpenTimeout  string  `yaml:"halfOpenTimeout" json:"half_open_timeout"`
+	}
+
+	var tmp alias
+	if err = unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	if cb == nil {
+		*cb = CircuitBreaker{}
+	}
+
+	cb.Enabled = tmp.Enabled
+	cb.MinRequests = tmp.MinRequests
+	cb.FailureRate = tmp.FailureRate
+	cb.SlowCallRate = tmp.SlowCallRate
+	cb.HalfOpenRequests = tmp.HalfOpenRequests
+
+	if len(tmp.Window) > 0 {
+		cb.Window, err = str2duration.ParseDuration(tmp.Window)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.SlowCallDuration) > 0 {
+		cb.SlowCallDuration, err = str2duration.ParseDuration(tmp.SlowCallDuration)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.OpenTimeout) > 0 {
+		cb.OpenTimeout, err = str2duration.ParseDuration(tmp.OpenTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.HalfOpenTimeout) > 0 {
+		cb.HalfOpenTimeout, err = str2duration.ParseDuration(tmp.HalfOpenTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package configs
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/xhit/go-str2duration/v2"
+)
+
+// FaultInjection configures the faults injected into the gRPC calls and the HTTP requests,
+// the faults are injected only while the injection is active and it can be toggled at runtime.
+// The configs of the same Name share the injector, e.g. the gRPC and the HTTP transport ones.
+// The bearer token of the admin endpoint is the AdminToken or the content of the AdminTokenFile,
+// the endpoint isn't mounted without it, the AdminToken isn't serialized.
+type FaultInjection struct {
+	Enabled        bool        `yaml:"enabled" json:"enabled"`
+	Active         bool        `yaml:"active" json:"active"`
+	Name           string      `yaml:"name,omitempty" json:"name,omitempty"`
+	Rules          []FaultRule `yaml:"rules,omitempty" json:"rules,omitempty" validate:"omitempty,dive"`
+	AdminToken     string      `yaml:"-" json:"-"`
+	AdminTokenFile string      `yaml:"adminTokenFile,omitempty" json:"admin_token_file,omitempty" validate:"omitempty,file"`
+}
+
+// FaultRule injects the fault into the percentage of the calls of the Methods and the Clusters (all of them when empty),
+// the Methods items are the gRPC method keys (see GrpcMethodKeys) or the HTTP request path prefixes.
+// The calls are delayed by the Latency and then fail with the Code (gRPC name, e.g. "UNAVAILABLE")
+// or the HTTPStatus, or their responses are dropped.
+type FaultRule struct {
+	Methods    []string      `yaml:"methods,omitempty" json:"methods,omitempty"`
+	Clusters   []string      `yaml:"clusters,omitempty" json:"clusters,omitempty"`
+	Percentage float64       `yaml:"percentage" json:"percentage" validate:"gte=0,lte=100"`
+	Latency    time.Duration `yaml:"latency,omitempty" json:"latency,omitempty" validate:"gte=0"`
+	Code       string        `yaml:"code,omitempty" json:"code,omitempty" validate:"omitempty,grpc_code"`
+	HTTPStatus int           `yaml:"httpStatus,omitempty" json:"http_status,omitempty" validate:"omitempty,gte=100,lte=599"`
+	Drop       bool          `yaml:"drop" json:"drop"`
+}
+
+func (fr *FaultRule) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		Methods    []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+		Clusters   []string `yaml:"clusters,omitempty" json:"clusters,omitempty"`
+		Percentage float64  `yaml:"percentage" json:"percentage"`
+		Latency    string   `yaml:"latency,omitempty" json:"latency,omitempty"`
+		Code       string   `yaml:"code,omitempty" json:"code,omitempty"`
+		HTTPStatus int      `yaml:"httpStatus,omitempty" json:"http_status,omitempty"`
+		Drop       bool     `yaml:"drop" json:"drop"`
+	}
+
+	if fr == nil {
+		*fr = FaultRule{}
+	}
+
+	return json.Marshal(alias{
+		Methods:    fr.Methods,
+		Clusters:   fr.Clusters,
+		Percentage: fr.Percentage,
+		Latency:    OptionalHumanDuration(fr.Latency),
+		Code:       fr.Code,
+		HTTPStatus: fr.HTTPStatus,
+		Drop:       fr.Drop,
+	})
+}
+
+func (fr *FaultRule) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		Methods    []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+		Clusters   []string `yaml:"clusters,omitempty" json:"clusters,omitempty"`
+		Percentage float64  `yaml:"percentage" json:"percentage"`
+		Latency    string   `yaml:"latency,omitempty" json:"latency,omitempty"`
+		Code       string   `yaml:"code,omitempty" json:"code,omitempty"`
+		HTTPStatus int      `yaml:"httpStatus,omitempty" json:"http_status,omitempty"`
+		Drop       bool     `yaml:"drop" json:"drop"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if fr == nil {
+		*fr = FaultRule{}
+	}
+
+	fr.Methods = tmp.Methods
+	fr.Clusters = tmp.Clusters
+	fr.Percentage = tmp.Percentage
+	fr.Code = tmp.Code
+	fr.HTTPStatus = tmp.HTTPStatus
+	fr.Drop = tmp.Drop
+
+	if len(tmp.Latency) > 0 {
+		fr.Latency, err = str2duration.ParseDuration(tmp.Latency)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (fr *FaultRule) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		Methods    []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+		Clusters   []string `yaml:"clusters,omitempty" json:"clusters,omitempty"`
+		Percentage float64  `yaml:"percentage" json:"percentage"`
+		Latency    string   `yaml:"latency,omitempty" json:"latency,omitempty"`
+		Code       string   `yaml:"code,omitempty" json:"code,omitempty"`
+		HTTPStatus int      `yaml:"httpStatus,omitempty" json:"http_status,omitempty"`
+		Drop       bool     `yaml:"drop" json:"drop"`
+	}
+
+	if fr == nil {
+		*fr = FaultRule{}
+	}
+
+	return alias{
+		Methods:    fr.Methods,
+		Clusters:   fr.Clusters,
+		Percentage: fr.Percentage,
+		Latency:    OptionalHumanDuration(fr.Latency),
+		Code:       fr.Code,
+		HTTPStatus: fr.HTTPStatus,
+		Drop:       fr.Drop,
+	}, nil
+}
+
+func (fr *FaultRule) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	type alias struct {
+		Methods    []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+		Clusters   []string `yaml:"clusters,omitempty" json:"clusters,omitempty"`
+		Percentage float64  `yaml:"percentage" json:"percentage"`
+		Latency    string   `yaml:"latency,omitempty" json:"latency,omitempty"`
+		Code       string   `yaml:"code,omitempty" json:"code,omitempty"`
+		HTTPStatus int      `yaml:"httpStatus,omitempty" json:"http_status,omitempty"`
+		Drop       bool     `yaml:"drop" json:"drop"`
+	}
+
+	var tmp alias
+	if err = unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	if fr == nil {
+		*fr = FaultRule{}
+	}
+
+	fr.Methods = tmp.Methods
+	fr.Clusters = tmp.Clusters
+	fr.Percentage = tmp.Percentage
+	fr.Code = tmp.Code
+	fr.HTTPStatus = tmp.HTTPStatus
+	fr.Drop = tmp.Drop
+
+	if len(tmp.Latency) > 0 {
+		fr.Latency, err = str2duration.ParseDuration(tmp.Latency)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
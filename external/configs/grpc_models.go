@@ -0,0 +1,1022 @@
+package configs
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/xhit/go-str2duration/v2"
+
+	"github.com/dysnix/predictkube-libs/external/enums"
+)
+
+type Retry struct {
+	Enabled bool                    `yaml:"enabled" json:"enabled"`
+	Policy  *RetryPolicy            `yaml:"policy" json:"policy" validate:"required_if=Enabled true"`
+	Methods map[string]*RetryPolicy `yaml:"methods,omitempty" json:"methods,omitempty" validate:"omitempty,dive"`
+}
+
+// PolicyFor returns the retry policy of the full gRPC method name,
+// the Methods keys can be "/package.Service/Method", "package.Service/Method" or "package.Service".
+func (r *Retry) PolicyFor(fullMethod string) *RetryPolicy {
+	if r == nil {
+		return nil
+	}
+
+	for _, key := range GrpcMethodKeys(fullMethod) {
+		if policy, ok := r.Methods[key]; ok && policy != nil {
+			return policy
+		}
+	}
+
+	return r.Policy
+}
+
+type RetryPolicy struct {
+	MaxAttempts       uint          `yaml:"maxAttempts" json:"max_attempts" validate:"required,gte=1"`
+	InitialBackoff    time.Duration `yaml:"initialBackoff" json:"initial_backoff" validate:"omitempty,gt=0"`
+	MaxBackoff        time.Duration `yaml:"maxBackoff" json:"max_backoff" validate:"omitempty,gtefield=InitialBackoff"`
+	BackoffMultiplier float64       `yaml:"backoffMultiplier" json:"backoff_multiplier" validate:"omitempty,gte=1"`
+	Jitter            float64       `yaml:"jitter" json:"jitter" validate:"gte=0,lte=1"`
+	RetryableCodes    []string      `yaml:"retryableCodes" json:"retryable_codes" validate:"dive,grpc_code"`
+	Idempotent        bool          `yaml:"idempotent" json:"idempotent"`
+}
+
+func (rp *RetryPolicy) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		MaxAttempts       uint     `yaml:"maxAttempts" json:"max_attempts"`
+		InitialBackoff    string   `yaml:"initialBackoff" json:"initial_backoff"`
+		MaxBackoff        string   `yaml:"maxBackoff" json:"max_backoff"`
+		BackoffMultiplier float64  `yaml:"backoffMultiplier" json:"backoff_multiplier"`
+		Jitter            float64  `yaml:"jitter" json:"jitter"`
+		RetryableCodes    []string `yaml:"retryableCodes" json:"retryable_codes"`
+		Idempotent        bool     `yaml:"idempotent" json:"idempotent"`
+	}
+
+	if rp == nil {
+		*rp = RetryPolicy{}
+	}
+
+	return json.Marshal(alias{
+		MaxAttempts:       rp.MaxAttempts,
+		InitialBackoff:    HumanDuration(rp.InitialBackoff),
+		MaxBackoff:        HumanDuration(rp.MaxBackoff),
+		BackoffMultiplier: rp.BackoffMultiplier,
+		Jitter:            rp.Jitter,
+		RetryableCodes:    rp.RetryableCodes,
+		Idempotent:        rp.Idempotent,
+	})
+}
+
+func (rp *RetryPolicy) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		MaxAttempts       uint     `yaml:"maxAttempts" json:"max_attempts"`
+		InitialBackoff    string   `yaml:"initialBackoff" json:"initial_backoff"`
+		MaxBackoff        string   `yaml:"maxBackoff" json:"max_backoff"`
+		BackoffMultiplier float64  `yaml:"backoffMultiplier" json:"backoff_multiplier"`
+		Jitter            float64  `yaml:"jitter" json:"jitter"`
+		RetryableCodes    []string `yaml:"retryableCodes" json:"retryable_codes"`
+		Idempotent        bool     `yaml:"idempotent" json:"idempotent"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if rp == nil {
+		*rp = RetryPolicy{}
+	}
+
+	rp.MaxAttempts = tmp.MaxAttempts
+	rp.BackoffMultiplier = tmp.BackoffMultiplier
+	rp.Jitter = tmp.Jitter
+	rp.RetryableCodes = tmp.RetryableCodes
+	rp.Idempotent = tmp.Idempotent
+
+	if len(tmp.InitialBackoff) > 0 {
+		rp.InitialBackoff, err = str2duration.ParseDuration(tmp.InitialBackoff)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.MaxBackoff) > 0 {
+		rp.MaxBackoff, err = str2duration.ParseDuration(tmp.MaxBackoff)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rp *RetryPolicy) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		MaxAttempts       uint     `yaml:"maxAttempts" json:"max_attempts"`
+		InitialBackoff    string   `yaml:"initialBackoff" json:"initial_backoff"`
+		MaxBackoff        string   `yaml:"maxBackoff" json:"max_backoff"`
+		BackoffMultiplier float64  `yaml:"backoffMultiplier" json:"backoff_multiplier"`
+		Jitter            float64  `yaml:"jitter" json:"jitter"`
+		RetryableCodes    []string `yaml:"retryableCodes" json:"retryable_codes"`
+		Idempotent        bool     `yaml:"idempotent" json:"idempotent"`
+	}
+
+	if rp == nil {
+		*rp = RetryPolicy{}
+	}
+
+	return alias{
+		MaxAttempts:       rp.MaxAttempts,
+		InitialBackoff:    HumanDuration(rp.InitialBackoff),
+		MaxBackoff:        HumanDuration(rp.MaxBackoff),
+		BackoffMultiplier: rp.BackoffMultiplier,
+		Jitter:            rp.Jitter,
+		RetryableCodes:    rp.RetryableCodes,
+		Idempotent:        rp.Idempotent,
+	}, nil
+}
+
+func (rp *RetryPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type alias struct {
+		MaxAttempts       uint     `yaml:"maxAttempts" json:"max_attempts"`
+		InitialBackoff    string   `yaml:"initialBackoff" json:"initial_backoff"`
+		MaxBackoff        string   `yaml:"maxBackoff" json:"max_backoff"`
+		BackoffMultiplier float64  `yaml:"backoffMultiplier" json:"backoff_multiplier"`
+		Jitter            float64  `yaml:"jitter" json:"jitter"`
+		RetryableCodes    []string `yaml:"retryableCodes" json:"retryable_codes"`
+		Idempotent        bool     `yaml:"idempotent" json:"idempotent"`
+	}
+
+	var tmp alias
+	err := unmarshal(&tmp)
+	if err != nil {
+		return err
+	}
+
+	if rp == nil {
+		*rp = RetryPolicy{}
+	}
+
+	rp.MaxAttempts = tmp.MaxAttempts
+	rp.BackoffMultiplier = tmp.BackoffMultiplier
+	rp.Jitter = tmp.Jitter
+	rp.RetryableCodes = tmp.RetryableCodes
+	rp.Idempotent = tmp.Idempotent
+
+	if len(tmp.InitialBackoff) > 0 {
+		rp.InitialBackoff, err = str2duration.ParseDuration(tmp.InitialBackoff)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.MaxBackoff) > 0 {
+		rp.MaxBackoff, err = str2duration.ParseDuration(tmp.MaxBackoff)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type Deadlines struct {
+	Default    time.Duration            `yaml:"default" json:"default" validate:"gte=0"`
+	Methods    map[string]time.Duration `yaml:"methods,omitempty" json:"methods,omitempty" validate:"omitempty,dive,gt=0"`
+	MaxHandler time.Duration            `yaml:"maxHandler" json:"max_handler" validate:"gte=0"`
+	Handlers   map[string]time.Duration `yaml:"handlers,omitempty" json:"handlers,omitempty" validate:"omitempty,dive,gt=0"`
+}
+
+// TimeoutFor returns the default deadline of the full gRPC method name applied by clients,
+// the Methods keys can be "/package.Service/Method", "package.Service/Method" or "package.Service".
+func (d *Deadlines) TimeoutFor(fullMethod string) time.Duration {
+	if d == nil {
+		return 0
+	}
+
+	for _, key := range GrpcMethodKeys(fullMethod) {
+		if timeout, ok := d.Methods[key]; ok && timeout > 0 {
+			return timeout
+		}
+	}
+
+	return d.Default
+}
+
+// HandlerTimeoutFor returns the maximum handler duration of the full gRPC method name enforced by servers,
+// the Handlers keys follow the Methods format and are capped by MaxHandler.
+func (d *Deadlines) HandlerTimeoutFor(fullMethod string) time.Duration {
+	if d == nil {
+		return 0
+	}
+
+	for _, key := range GrpcMethodKeys(fullMethod) {
+		if timeout, ok := d.Handlers[key]; ok && timeout > 0 {
+			if d.MaxHandler > 0 && timeout > d.MaxHandler {
+				return d.MaxHandler
+			}
+
+			return timeout
+		}
+	}
+
+	return d.MaxHandler
+}
+
+func (d *Deadlines) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		Default    string            `yaml:"default" json:"default"`
+		Methods    map[string]string `yaml:"methods,omitempty" json:"methods,omitempty"`
+		MaxHandler string            `yaml:"maxHandler" json:"max_handler"`
+		Handlers   map[string]string `yaml:"handlers,omitempty" json:"handlers,omitempty"`
+	}
+
+	if d == nil {
+		*d = Deadlines{}
+	}
+
+	return json.Marshal(alias{
+		Default:    HumanDuration(d.Default),
+		Methods:    marshalDurationsMap(d.Methods),
+		MaxHandler: HumanDuration(d.MaxHandler),
+		Handlers:   marshalDurationsMap(d.Handlers),
+	})
+}
+
+func (d *Deadlines) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		Default    string            `yaml:"default" json:"default"`
+		Methods    map[string]string `yaml:"methods,omitempty" json:"methods,omitempty"`
+		MaxHandler string            `yaml:"maxHandler" json:"max_handler"`
+		Handlers   map[string]string `yaml:"handlers,omitempty" json:"handlers,omitempty"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if d == nil {
+		*d = Deadlines{}
+	}
+
+	if len(tmp.Default) > 0 {
+		d.Default, err = str2duration.ParseDuration(tmp.Default)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.MaxHandler) > 0 {
+		d.MaxHandler, err = str2duration.ParseDuration(tmp.MaxHandler)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.Methods, err = unmarshalDurationsMap(tmp.Methods); err != nil {
+		return err
+	}
+
+	d.Handlers, err = unmarshalDurationsMap(tmp.Handlers)
+
+	return err
+}
+
+func (d *Deadlines) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		Default    string            `yaml:"default" json:"default"`
+		Methods    map[string]string `yaml:"methods,omitempty" json:"methods,omitempty"`
+		MaxHandler string            `yaml:"maxHandler" json:"max_handler"`
+		Handlers   map[string]string `yaml:"handlers,omitempty" json:"handlers,omitempty"`
+	}
+
+	if d == nil {
+		*d = Deadlines{}
+	}
+
+	return alias{
+		Default:    HumanDuration(d.Default),
+		Methods:    marshalDurationsMap(d.Methods),
+		MaxHandler: HumanDuration(d.MaxHandler),
+		Handlers:   marshalDurationsMap(d.Handlers),
+	}, nil
+}
+
+func (d *Deadlines) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type alias struct {
+		Default    string            `yaml:"default" json:"default"`
+		Methods    map[string]string `yaml:"methods,omitempty" json:"methods,omitempty"`
+		MaxHandler string            `yaml:"maxHandler" json:"max_handler"`
+		Handlers   map[string]string `yaml:"handlers,omitempty" json:"handlers,omitempty"`
+	}
+
+	var tmp alias
+	err := unmarshal(&tmp)
+	if err != nil {
+		return err
+	}
+
+	if d == nil {
+		*d = Deadlines{}
+	}
+
+	if len(tmp.Default) > 0 {
+		d.Default, err = str2duration.ParseDuration(tmp.Default)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.MaxHandler) > 0 {
+		d.MaxHandler, err = str2duration.ParseDuration(tmp.MaxHandler)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.Methods, err = unmarshalDurationsMap(tmp.Methods); err != nil {
+		return err
+	}
+
+	d.Handlers, err = unmarshalDurationsMap(tmp.Handlers)
+
+	return err
+}
+
+func marshalDurationsMap(in map[string]time.Duration) map[string]string {
+	if in == nil {
+		return nil
+	}
+
+	out := make(map[string]string, len(in))
+	for key, d := range in {
+		out[key] = HumanDuration(d)
+	}
+
+	return out
+}
+
+func unmarshalDurationsMap(in map[string]string) (out map[string]time.Duration, err error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	out = make(map[string]time.Duration, len(in))
+	for key, str := range in {
+		if out[key], err = str2duration.ParseDuration(str); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+type Logging struct {
+	Enabled         bool     `yaml:"enabled" json:"enabled"`
+	SampleRate      float64  `yaml:"sampleRate" json:"sample_rate" validate:"gte=0,lte=1"`
+	SuppressMethods []string `yaml:"suppressMethods,omitempty" json:"suppress_methods,omitempty"`
+}
+
+// Suppressed reports whether the successful calls of the full gRPC method name mustn't be logged.
+func (l *Logging) Suppressed(fullMethod string) bool {
+	if l == nil {
+		return false
+	}
+
+	for _, key := range GrpcMethodKeys(fullMethod) {
+		for _, method := range l.SuppressMethods {
+			if method == key {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type TLS struct {
+	CertFile       string               `yaml:"certFile,omitempty" json:"cert_file,omitempty" validate:"required_with=KeyFile"`
+	KeyFile        string               `yaml:"keyFile,omitempty" json:"key_file,omitempty" validate:"required_with=CertFile"`
+	CAFile         string               `yaml:"caFile,omitempty" json:"ca_file,omitempty"`
+	ServerName     string               `yaml:"serverName,omitempty" json:"server_name,omitempty"`
+	ClientAuth     enums.ClientAuthType `yaml:"clientAuth" json:"client_auth"`
+	MinVersion     string               `yaml:"minVersion,omitempty" json:"min_version,omitempty" validate:"omitempty,tls_version"`
+	ReloadInterval time.Duration        `yaml:"reloadInterval" json:"reload_interval" validate:"gte=0"`
+}
+
+func (t *TLS) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		CertFile       string               `yaml:"certFile,omitempty" json:"cert_file,omitempty"`
+		KeyFile        string               `yaml:"keyFile,omitempty" json:"key_file,omitempty"`
+		CAFile         string               `yaml:"caFile,omitempty" json:"ca_file,omitempty"`
+		ServerName     string               `yaml:"serverName,omitempty" json:"server_name,omitempty"`
+		ClientAuth     enums.ClientAuthType `yaml:"clientAuth" json:"client_auth"`
+		MinVersion     string               `yaml:"minVersion,omitempty" json:"min_version,omitempty"`
+		ReloadInterval string               `yaml:"reloadInterval" json:"reload_interval"`
+	}
+
+	if t == nil {
+		*t = TLS{}
+	}
+
+	return json.Marshal(alias{
+		CertFile:       t.CertFile,
+		KeyFile:        t.KeyFile,
+		CAFile:         t.CAFile,
+		ServerName:     t.ServerName,
+		ClientAuth:     t.ClientAuth,
+		MinVersion:     t.MinVersion,
+		ReloadInterval: HumanDuration(t.ReloadInterval),
+	})
+}
+
+func (t *TLS) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		CertFile       string               `yaml:"certFile,omitempty" json:"cert_file,omitempty"`
+		KeyFile        string               `yaml:"keyFile,omitempty" json:"key_file,omitempty"`
+		CAFile         string               `yaml:"caFile,omitempty" json:"ca_file,omitempty"`
+		ServerName     string               `yaml:"serverName,omitempty" json:"server_name,omitempty"`
+		ClientAuth     enums.ClientAuthType `yaml:"clientAuth" json:"client_auth"`
+		MinVersion     string               `yaml:"minVersion,omitempty" json:"min_version,omitempty"`
+		ReloadInterval string               `yaml:"reloadInterval" json:"reload_interval"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if t == nil {
+		*t = TLS{}
+	}
+
+	t.CertFile = tmp.CertFile
+	t.KeyFile = tmp.KeyFile
+	t.CAFile = tmp.CAFile
+	t.ServerName = tmp.ServerName
+	t.ClientAuth = tmp.ClientAuth
+	t.MinVersion = tmp.MinVersion
+
+	if len(tmp.ReloadInterval) > 0 {
+		t.ReloadInterval, err = str2duration.ParseDuration(tmp.ReloadInterval)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *TLS) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		CertFile       string               `yaml:"certFile,omitempty" json:"cert_file,omitempty"`
+		KeyFile        string               `yaml:"keyFile,omitempty" json:"key_file,omitempty"`
+		CAFile         string               `yaml:"caFile,omitempty" json:"ca_file,omitempty"`
+		ServerName     string               `yaml:"serverName,omitempty" json:"server_name,omitempty"`
+		ClientAuth     enums.ClientAuthType `yaml:"clientAuth" json:"client_auth"`
+		MinVersion     string               `yaml:"minVersion,omitempty" json:"min_version,omitempty"`
+		ReloadInterval string               `yaml:"reloadInterval" json:"reload_interval"`
+	}
+
+	if t == nil {
+		*t = TLS{}
+	}
+
+	return alias{
+		CertFile:       t.CertFile,
+		KeyFile:        t.KeyFile,
+		CAFile:         t.CAFile,
+		ServerName:     t.ServerName,
+		ClientAuth:     t.ClientAuth,
+		MinVersion:     t.MinVersion,
+		ReloadInterval: HumanDuration(t.ReloadInterval),
+	}, nil
+}
+
+func (t *TLS) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type alias struct {
+		CertFile       string               `yaml:"certFile,omitempty" json:"cert_file,omitempty"`
+		KeyFile        string               `yaml:"keyFile,omitempty" json:"key_file,omitempty"`
+		CAFile         string               `yaml:"caFile,omitempty" json:"ca_file,omitempty"`
+		ServerName     string               `yaml:"serverName,omitempty" json:"server_name,omitempty"`
+		ClientAuth     enums.ClientAuthType `yaml:"clientAuth" json:"client_auth"`
+		MinVersion     string               `yaml:"minVersion,omitempty" json:"min_version,omitempty"`
+		ReloadInterval string               `yaml:"reloadInterval" json:"reload_interval"`
+	}
+
+	var tmp alias
+	err := unmarshal(&tmp)
+	if err != nil {
+		return err
+	}
+
+	if t == nil {
+		*t = TLS{}
+	}
+
+	t.CertFile = tmp.CertFile
+	t.KeyFile = tmp.KeyFile
+	t.CAFile = tmp.CAFile
+	t.ServerName = tmp.ServerName
+	t.ClientAuth = tmp.ClientAuth
+	t.MinVersion = tmp.MinVersion
+
+	if len(tmp.ReloadInterval) > 0 {
+		t.ReloadInterval, err = str2duration.ParseDuration(tmp.ReloadInterval)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Shutdown configures the server stop, the server is draining for the DrainPeriod before the graceful stop
+// and it is stopped forcibly once the pending calls aren't finished in the Timeout.
+type Shutdown struct {
+	Timeout     time.Duration `yaml:"timeout" json:"timeout" validate:"gte=0"`
+	DrainPeriod time.Duration `yaml:"drainPeriod,omitempty" json:"drain_period,omitempty" validate:"gte=0"`
+}
+
+func (s *Shutdown) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		Timeout     string `yaml:"timeout" json:"timeout"`
+		DrainPeriod string `yaml:"drainPeriod,omitempty" json:"drain_period,omitempty"`
+	}
+
+	if s == nil {
+		*s = Shutdown{}
+	}
+
+	return json.Marshal(alias{
+		Timeout:     HumanDuration(s.Timeout),
+		DrainPeriod: OptionalHumanDuration(s.DrainPeriod),
+	})
+}
+
+func (s *Shutdown) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		Timeout     string `yaml:"timeout" json:"timeout"`
+		DrainPeriod string `yaml:"drainPeriod,omitempty" json:"drain_period,omitempty"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if s == nil {
+		*s = Shutdown{}
+	}
+
+	if len(tmp.Timeout) > 0 {
+		s.Timeout, err = str2duration.ParseDuration(tmp.Timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.DrainPeriod) > 0 {
+		s.DrainPeriod, err = str2duration.ParseDuration(tmp.DrainPeriod)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Shutdown) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		Timeout     string `yaml:"timeout" json:"timeout"`
+		DrainPeriod string `yaml:"drainPeriod,omitempty" json:"drain_period,omitempty"`
+	}
+
+	if s == nil {
+		*s = Shutdown{}
+	}
+
+	return alias{
+		Timeout:     HumanDuration(s.Timeout),
+		DrainPeriod: OptionalHumanDuration(s.DrainPeriod),
+	}, nil
+}
+
+func (s *Shutdown) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	type alias struct {
+		Timeout     string `yaml:"timeout" json:"timeout"`
+		DrainPeriod string `yaml:"drainPeriod,omitempty" json:"drain_period,omitempty"`
+	}
+
+	var tmp alias
+	if err = unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	if s == nil {
+		*s = Shutdown{}
+	}
+
+	if len(tmp.Timeout) > 0 {
+		s.Timeout, err = str2duration.ParseDuration(tmp.Timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tmp.DrainPeriod) > 0 {
+		s.DrainPeriod, err = str2duration.ParseDuration(tmp.DrainPeriod)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type LoadBalancing struct {
+	Resolver          enums.ResolverType    `yaml:"resolver" json:"resolver"`
+	Policy            enums.BalancingPolicy `yaml:"policy" json:"policy"`
+	Endpoints         []string              `yaml:"endpoints,omitempty" json:"endpoints,omitempty" validate:"omitempty,dive,hostname_port"`
+	HealthCheck       bool                  `yaml:"healthCheck" json:"health_check"`
+	HealthServiceName string                `yaml:"healthServiceName,omitempty" json:"health_service_name,omitempty"`
+}
+
+// Idempotency configures the deduplication of the calls by the idempotency metadata key,
+// the server replays the stored result of the first call for the duplicates within the window.
+type Idempotency struct {
+	Enabled bool          `yaml:"enabled" json:"enabled"`
+	Window  time.Duration `yaml:"window" json:"window" validate:"gte=0"`
+	Methods []string      `yaml:"methods,omitempty" json:"methods,omitempty"`
+}
+
+// Matches reports whether the calls of the full gRPC method name are deduplicated,
+// the Methods items can be "/package.Service/Method", "package.Service/Method" or "package.Service",
+// all the methods are matched when the list is empty.
+func (i *Idempotency) Matches(fullMethod string) bool {
+	if i == nil || !i.Enabled {
+		return false
+	}
+
+	if len(i.Methods) == 0 {
+		return true
+	}
+
+	for _, key := range GrpcMethodKeys(fullMethod) {
+		for _, method := range i.Methods {
+			if method == key {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (i *Idempotency) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		Enabled bool     `yaml:"enabled" json:"enabled"`
+		Window  string   `yaml:"window" json:"window"`
+		Methods []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+	}
+
+	if i == nil {
+		*i = Idempotency{}
+	}
+
+	return json.Marshal(alias{
+		Enabled: i.Enabled,
+		Window:  HumanDuration(i.Window),
+		Methods: i.Methods,
+	})
+}
+
+func (i *Idempotency) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		Enabled bool     `yaml:"enabled" json:"enabled"`
+		Window  string   `yaml:"window" json:"window"`
+		Methods []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if i == nil {
+		*i = Idempotency{}
+	}
+
+	i.Enabled = tmp.Enabled
+	i.Methods = tmp.Methods
+
+	if len(tmp.Window) > 0 {
+		i.Window, err = str2duration.ParseDuration(tmp.Window)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *Idempotency) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		Enabled bool     `yaml:"enabled" json:"enabled"`
+		Window  string   `yaml:"window" json:"window"`
+		Methods []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+	}
+
+	if i == nil {
+		*i = Idempotency{}
+	}
+
+	return alias{
+		Enabled: i.Enabled,
+		Window:  HumanDuration(i.Window),
+		Methods: i.Methods,
+	}, nil
+}
+
+func (i *Idempotency) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	type alias struct {
+		Enabled bool     `yaml:"enabled" json:"enabled"`
+		Window  string   `yaml:"window" json:"window"`
+		Methods []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+	}
+
+	var tmp alias
+	if err = unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	if i == nil {
+		*i = Idempotency{}
+	}
+
+	i.Enabled = tmp.Enabled
+	i.Methods = tmp.Methods
+
+	if len(tmp.Window) > 0 {
+		i.Window, err = str2duration.ParseDuration(tmp.Window)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResponseCache configures the caching of the read-only unary calls, the Methods are the cached methods
+// with the TTLs of their responses and InvalidatedBy maps the mutating methods to the cached ones.
+type ResponseCache struct {
+	Enabled       bool                     `yaml:"enabled" json:"enabled"`
+	Methods       map[string]time.Duration `yaml:"methods" json:"methods" validate:"omitempty,dive,gt=0"`
+	InvalidatedBy map[string][]string      `yaml:"invalidatedBy,omitempty" json:"invalidated_by,omitempty"`
+}
+
+// MethodFor returns the Methods key and the TTL of the cached full gRPC method name, the keys can be
+// "/package.Service/Method", "package.Service/Method" or "package.Service", the TTL is zero for
+// the methods which are not cached.
+func (rc *ResponseCache) MethodFor(fullMethod string) (key string, ttl time.Duration) {
+	if rc == nil || !rc.Enabled {
+		return "", 0
+	}
+
+	for _, key = range GrpcMethodKeys(fullMethod) {
+		if ttl, ok := rc.Methods[key]; ok && ttl > 0 {
+			return key, ttl
+		}
+	}
+
+	return "", 0
+}
+
+// InvalidatedFor returns the Methods keys of the cached responses invalidated by the successful calls
+// of the full gRPC method name.
+func (rc *ResponseCache) InvalidatedFor(fullMethod string) []string {
+	if rc == nil || !rc.Enabled {
+		return nil
+	}
+
+	for _, key := range GrpcMethodKeys(fullMethod) {
+		if methods, ok := rc.InvalidatedBy[key]; ok {
+			return methods
+		}
+	}
+
+	return nil
+}
+
+func (rc *ResponseCache) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		Enabled       bool                `yaml:"enabled" json:"enabled"`
+		Methods       map[string]string   `yaml:"methods" json:"methods"`
+		InvalidatedBy map[string][]string `yaml:"invalidatedBy,omitempty" json:"invalidated_by,omitempty"`
+	}
+
+	if rc == nil {
+		*rc = ResponseCache{}
+	}
+
+	return json.Marshal(alias{
+		Enabled:       rc.Enabled,
+		Methods:       marshalDurationsMap(rc.Methods),
+		InvalidatedBy: rc.InvalidatedBy,
+	})
+}
+
+func (rc *ResponseCache) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		Enabled       bool                `yaml:"enabled" json:"enabled"`
+		Methods       map[string]string   `yaml:"methods" json:"methods"`
+		InvalidatedBy map[string][]string `yaml:"invalidatedBy,omitempty" json:"invalidated_by,omitempty"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if rc == nil {
+		*rc = ResponseCache{}
+	}
+
+	rc.Enabled = tmp.Enabled
+	rc.InvalidatedBy = tmp.InvalidatedBy
+
+	if rc.Methods, err = unmarshalDurationsMap(tmp.Methods); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (rc *ResponseCache) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		Enabled       bool                `yaml:"enabled" json:"enabled"`
+		Methods       map[string]string   `yaml:"methods" json:"methods"`
+		InvalidatedBy map[string][]string `yaml:"invalidatedBy,omitempty" json:"invalidated_by,omitempty"`
+	}
+
+	if rc == nil {
+		*rc = ResponseCache{}
+	}
+
+	return alias{
+		Enabled:       rc.Enabled,
+		Methods:       marshalDurationsMap(rc.Methods),
+		InvalidatedBy: rc.InvalidatedBy,
+	}, nil
+}
+
+func (rc *ResponseCache) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	type alias struct {
+		Enabled       bool                `yaml:"enabled" json:"enabled"`
+		Methods       map[string]string   `yaml:"methods" json:"methods"`
+		InvalidatedBy map[string][]string `yaml:"invalidatedBy,omitempty" json:"invalidated_by,omitempty"`
+	}
+
+	var tmp alias
+	if err = unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	if rc == nil {
+		*rc = ResponseCache{}
+	}
+
+	rc.Enabled = tmp.Enabled
+	rc.InvalidatedBy = tmp.InvalidatedBy
+
+	if rc.Methods, err = unmarshalDurationsMap(tmp.Methods); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TenantMetrics configures the server metrics labelled by the cluster id and the client name of the calls,
+// the label values out of the allowlists or the top MaxClusters/MaxClients are reported as "other".
+type TenantMetrics struct {
+	Enabled     bool      `yaml:"enabled" json:"enabled"`
+	Buckets     []float64 `yaml:"buckets,omitempty" json:"buckets,omitempty" validate:"omitempty,dive,gt=0"`
+	SizeBuckets []float64 `yaml:"sizeBuckets,omitempty" json:"size_buckets,omitempty" validate:"omitempty,dive,gt=0"`
+	Clusters    []string  `yaml:"clusters,omitempty" json:"clusters,omitempty"`
+	Clients     []string  `yaml:"clients,omitempty" json:"clients,omitempty"`
+	MaxClusters int       `yaml:"maxClusters,omitempty" json:"max_clusters,omitempty" validate:"gte=0"`
+	MaxClients  int       `yaml:"maxClients,omitempty" json:"max_clients,omitempty" validate:"gte=0"`
+}
+
+// Credentials configures the token source of the client per-RPC credentials, the token is read from the TokenFile
+// instead of the static client token and exchanged for the access token on the Endpoint when it is set.
+// The Insecure credentials are sent in plaintext, they are required by the insecure connections.
+type Credentials struct {
+	TokenFile     string        `yaml:"tokenFile,omitempty" json:"token_file,omitempty" validate:"omitempty,file"`
+	Endpoint      string        `yaml:"endpoint,omitempty" json:"endpoint,omitempty" validate:"omitempty,url"`
+	RefreshBefore time.Duration `yaml:"refreshBefore" json:"refresh_before" validate:"gte=0"`
+	Insecure      bool          `yaml:"insecure" json:"insecure"`
+}
+
+func (c *Credentials) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		TokenFile     string `yaml:"tokenFile,omitempty" json:"token_file,omitempty"`
+		Endpoint      string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+		RefreshBefore string `yaml:"refreshBefore" json:"refresh_before"`
+		Insecure      bool   `yaml:"insecure" json:"insecure"`
+	}
+
+	if c == nil {
+		*c = Credentials{}
+	}
+
+	return json.Marshal(alias{
+		TokenFile:     c.TokenFile,
+		Endpoint:      c.Endpoint,
+		RefreshBefore: HumanDuration(c.RefreshBefore),
+		Insecure:      c.Insecure,
+	})
+}
+
+func (c *Credentials) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		TokenFile     string `yaml:"tokenFile,omitempty" json:"token_file,omitempty"`
+		Endpoint      string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+		RefreshBefore string `yaml:"refreshBefore" json:"refresh_before"`
+		Insecure      bool   `yaml:"insecure" json:"insecure"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if c == nil {
+		*c = Credentials{}
+	}
+
+	c.TokenFile = tmp.TokenFile
+	c.Endpoint = tmp.Endpoint
+	c.Insecure = tmp.Insecure
+
+	if len(tmp.RefreshBefore) > 0 {
+		c.RefreshBefore, err = str2duration.ParseDuration(tmp.RefreshBefore)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Credentials) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		TokenFile     string `yaml:"tokenFile,omitempty" json:"token_file,omitempty"`
+		Endpoint      string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+		RefreshBefore string `yaml:"refreshBefore" json:"refresh_before"`
+		Insecure      bool   `yaml:"insecure" json:"insecure"`
+	}
+
+	if c == nil {
+		*c = Credentials{}
+	}
+
+	return alias{
+		TokenFile:     c.TokenFile,
+		Endpoint:      c.Endpoint,
+		RefreshBefore: HumanDuration(c.RefreshBefore),
+		Insecure:      c.Insecure,
+	}, nil
+}
+
+func (c *Credentials) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	type alias struct {
+		TokenFile     string `yaml:"tokenFile,omitempty" json:"token_file,omitempty"`
+		Endpoint      string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+		RefreshBefore string `yaml:"refreshBefore" json:"refresh_before"`
+		Insecure      bool   `yaml:"insecure" json:"insecure"`
+	}
+
+	var tmp alias
+	if err = unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	if c == nil {
+		*c = Credentials{}
+	}
+
+	c.TokenFile = tmp.TokenFile
+	c.Endpoint = tmp.Endpoint
+	c.Insecure = tmp.Insecure
+
+	if len(tmp.RefreshBefore) > 0 {
+		c.RefreshBefore, err = str2duration.ParseDuration(tmp.RefreshBefore)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
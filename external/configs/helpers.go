@@ -2,6 +2,7 @@ package configs
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/olekukonko/tablewriter"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 )
 
 func GetRootRepositoryPath() string {
@@ -50,6 +52,15 @@ func HumanDuration(d time.Duration) string {
 	return s
 }
 
+// OptionalHumanDuration is HumanDuration of the omitempty fields, the zero duration is the empty string.
+func OptionalHumanDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+
+	return HumanDuration(d)
+}
+
 func IsUrl(str string) bool {
 	urlStr, err := url.ParseRequestURI(str)
 	if err != nil {
@@ -214,3 +225,50 @@ func PrintFlags() {
 	})
 	table.Render()
 }
+
+// GrpcMethodKeys returns the lookup keys of per-method configuration maps for the full
+// gRPC method name ("/package.Service/Method"), from the most to the least specific.
+func GrpcMethodKeys(fullMethod string) []string {
+	name := strings.TrimPrefix(fullMethod, "/")
+	keys := []string{fullMethod}
+
+	if name != fullMethod {
+		keys = append(keys, name)
+	}
+
+	if i := strings.LastIndex(name, "/"); i > 0 {
+		keys = append(keys, name[:i])
+	}
+
+	return keys
+}
+
+// ParseGrpcCode parses a gRPC status code name in any of the "UNAVAILABLE", "Unavailable"
+// or "unavailable" forms.
+func ParseGrpcCode(name string) (codes.Code, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(name, "_", ""))
+
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.ToLower(c.String()) == normalized {
+			return c, nil
+		}
+	}
+
+	return codes.Unknown, fmt.Errorf("invalid grpc code: %q", name)
+}
+
+// ParseTLSVersion returns the TLS version by its name like "1.2" or "TLS1.3", an empty name means TLS 1.2.
+func ParseTLSVersion(name string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToUpper(name), "TLS") {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid tls version: %q", name)
+	}
+}
@@ -33,6 +33,11 @@ type ServerGetter interface {
 	GetServerConfigs() *Single
 }
 
+// BaseGetter is implemented by the server configs which carry the base configs, e.g. the tracing.
+type BaseGetter interface {
+	GetBase() *Base
+}
+
 type CacheGetter interface {
 	GetCache() *Cache
 }
@@ -179,6 +184,55 @@ func SetConn(conn *grpc.ClientConn) Option {
 	}
 }
 
+type GrpcServicesSetter interface {
+	AddServices(registers ...func(*grpc.Server))
+}
+
+type GrpcInterceptorsSetter interface {
+	AddInterceptors(interceptors ...grpc.UnaryServerInterceptor)
+}
+
+type GrpcServerSetters interface {
+	ConfigSetter
+	LoggerSetter
+	GrpcServicesSetter
+	GrpcInterceptorsSetter
+}
+
+type GrpcServerOption func(GrpcServerSetters) error
+
+func SetGrpcServerConfigs(conf SingleGetter) GrpcServerOption {
+	return func(r GrpcServerSetters) error {
+		if conf != nil {
+			r.SetConfigs(conf)
+		}
+		return nil
+	}
+}
+
+func SetGrpcServerLogger(logger *zap.SugaredLogger) GrpcServerOption {
+	return func(r GrpcServerSetters) error {
+		if logger != nil {
+			r.SetLogger(logger)
+		}
+		return nil
+	}
+}
+
+func RegisterGrpcServices(registers ...func(*grpc.Server)) GrpcServerOption {
+	return func(r GrpcServerSetters) error {
+		r.AddServices(registers...)
+		return nil
+	}
+}
+
+func SetGrpcServerInterceptors(interceptors ...grpc.UnaryServerInterceptor) GrpcServerOption {
+	return func(r GrpcServerSetters) error {
+		r.AddInterceptors(interceptors...)
+		return nil
+	}
+}
+
 type TransportGetter interface {
 	GetTransportConfigs() *HTTPTransport
 }
@@ -0,0 +1,199 @@
+package configs
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/xhit/go-str2duration/v2"
+
+	"github.com/dysnix/predictkube-libs/external/enums"
+)
+
+// PriorityClass is the class of the server calls of the Methods or the Clients names, the calls of the class
+// are rejected once the in-flight calls fill the Share of the concurrency limit, so the classes of the lower
+// shares are shed first.
+type PriorityClass struct {
+	Name    string   `yaml:"name" json:"name" validate:"required"`
+	Methods []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+	Clients []string `yaml:"clients,omitempty" json:"clients,omitempty"`
+	Share   float64  `yaml:"share" json:"share" validate:"gt=0,lte=1"`
+}
+
+// Matches reports whether the call of the full gRPC method name and the client name belongs to the class,
+// the Methods items can be "/package.Service/Method", "package.Service/Method" or "package.Service".
+func (pc *PriorityClass) Matches(fullMethod, client string) bool {
+	for _, name := range pc.Clients {
+		if name == client {
+			return true
+		}
+	}
+
+	for _, key := range GrpcMethodKeys(fullMethod) {
+		for _, method := range pc.Methods {
+			if method == key {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ConcurrencyLimit configures the limit of the in-flight server calls, the Limit is the static limit
+// or the initial one of the adaptive modes kept within the MinLimit and the MaxLimit.
+// The AIMD mode backs the limit off by the BackoffRatio on the failed calls and the calls slower than
+// the LatencyThreshold, the Gradient mode allows the latency to grow by the Tolerance ratio.
+// The zero values are replaced by the defaults of the limiter package.
+type ConcurrencyLimit struct {
+	Enabled          bool              `yaml:"enabled" json:"enabled"`
+	Mode             enums.LimiterMode `yaml:"mode" json:"mode"`
+	Limit            uint              `yaml:"limit" json:"limit"`
+	MinLimit         uint              `yaml:"minLimit" json:"min_limit"`
+	MaxLimit         uint              `yaml:"maxLimit" json:"max_limit" validate:"omitempty,gtefield=MinLimit"`
+	LatencyThreshold time.Duration     `yaml:"latencyThreshold" json:"latency_threshold" validate:"gte=0"`
+	BackoffRatio     float64           `yaml:"backoffRatio" json:"backoff_ratio" validate:"gte=0,lt=1"`
+	Tolerance        float64           `yaml:"tolerance" json:"tolerance" validate:"omitempty,gte=1"`
+	Classes          []PriorityClass   `yaml:"classes,omitempty" json:"classes,omitempty" validate:"omitempty,dive"`
+}
+
+func (cl *ConcurrencyLimit) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		Enabled          bool              `yaml:"enabled" json:"enabled"`
+		Mode             enums.LimiterMode `yaml:"mode" json:"mode"`
+		Limit            uint              `yaml:"limit" json:"limit"`
+		MinLimit         uint              `yaml:"minLimit" json:"min_limit"`
+		MaxLimit         uint              `yaml:"maxLimit" json:"max_limit"`
+		LatencyThreshold string            `yaml:"latencyThreshold" json:"latency_threshold"`
+		BackoffRatio     float64           `yaml:"backoffRatio" json:"backoff_ratio"`
+		Tolerance        float64           `yaml:"tolerance" json:"tolerance"`
+		Classes          []PriorityClass   `yaml:"classes,omitempty" json:"classes,omitempty"`
+	}
+
+	if cl == nil {
+		*cl = ConcurrencyLimit{}
+	}
+
+	return json.Marshal(alias{
+		Enabled:          cl.Enabled,
+		Mode:             cl.Mode,
+		Limit:            cl.Limit,
+		MinLimit:         cl.MinLimit,
+		MaxLimit:         cl.MaxLimit,
+		LatencyThreshold: HumanDuration(cl.LatencyThreshold),
+		BackoffRatio:     cl.BackoffRatio,
+		Tolerance:        cl.Tolerance,
+		Classes:          cl.Classes,
+	})
+}
+
+func (cl *ConcurrencyLimit) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		Enabled          bool              `yaml:"enabled" json:"enabled"`
+		Mode             enums.LimiterMode `yaml:"mode" json:"mode"`
+		Limit            uint              `yaml:"limit" json:"limit"`
+		MinLimit         uint              `yaml:"minLimit" json:"min_limit"`
+		MaxLimit         uint              `yaml:"maxLimit" json:"max_limit"`
+		LatencyThreshold string            `yaml:"latencyThreshold" json:"latency_threshold"`
+		BackoffRatio     float64           `yaml:"backoffRatio" json:"backoff_ratio"`
+		Tolerance        float64           `yaml:"tolerance" json:"tolerance"`
+		Classes          []PriorityClass   `yaml:"classes,omitempty" json:"classes,omitempty"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if cl == nil {
+		*cl = ConcurrencyLimit{}
+	}
+
+	cl.Enabled = tmp.Enabled
+	cl.Mode = tmp.Mode
+	cl.Limit = tmp.Limit
+	cl.MinLimit = tmp.MinLimit
+	cl.MaxLimit = tmp.MaxLimit
+	cl.BackoffRatio = tmp.BackoffRatio
+	cl.Tolerance = tmp.Tolerance
+	cl.Classes = tmp.Classes
+
+	if len(tmp.LatencyThreshold) > 0 {
+		cl.LatencyThreshold, err = str2duration.ParseDuration(tmp.LatencyThreshold)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cl *ConcurrencyLimit) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		Enabled          bool              `yaml:"enabled" json:"enabled"`
+		Mode             enums.LimiterMode `yaml:"mode" json:"mode"`
+		Limit            uint              `yaml:"limit" json:"limit"`
+		MinLimit         uint              `yaml:"minLimit" json:"min_limit"`
+		MaxLimit         uint              `yaml:"maxLimit" json:"max_limit"`
+		LatencyThreshold string            `yaml:"latencyThreshold" json:"latency_threshold"`
+		BackoffRatio     float64           `yaml:"backoffRatio" json:"backoff_ratio"`
+		Tolerance        float64           `yaml:"tolerance" json:"tolerance"`
+		Classes          []PriorityClass   `yaml:"classes,omitempty" json:"classes,omitempty"`
+	}
+
+	if cl == nil {
+		*cl = ConcurrencyLimit{}
+	}
+
+	return alias{
+		Enabled:          cl.Enabled,
+		Mode:             cl.Mode,
+		Limit:            cl.Limit,
+		MinLimit:         cl.MinLimit,
+		MaxLimit:         cl.MaxLimit,
+		LatencyThreshold: HumanDuration(cl.LatencyThreshold),
+		BackoffRatio:     cl.BackoffRatio,
+		Tolerance:        cl.Tolerance,
+		Classes:          cl.Classes,
+	}, nil
+}
+
+func (cl *ConcurrencyLimit) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	type alias struct {
+		Enabled          bool              `yaml:"enabled" json:"enabled"`
+		Mode             enums.LimiterMode `yaml:"mode" json:"mode"`
+		Limit            uint              `yaml:"limit" json:"limit"`
+		MinLimit         uint              `yaml:"minLimit" json:"min_limit"`
+		MaxLimit         uint              `yaml:"maxLimit" json:"max_limit"`
+		LatencyThreshold string            `yaml:"latencyThreshold" json:"latency_threshold"`
+		BackoffRatio     float64           `yaml:"backoffRatio" json:"backoff_ratio"`
+		Tolerance        float64           `yaml:"tolerance" json:"tolerance"`
+		Classes          []PriorityClass   `yaml:"classes,omitempty" json:"classes,omitempty"`
+	}
+
+	var tmp alias
+	if err = unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	if cl == nil {
+		*cl = ConcurrencyLimit{}
+	}
+
+	cl.Enabled = tmp.Enabled
+	cl.Mode = tmp.Mode
+	cl.Limit = tmp.Limit
+	cl.MinLimit = tmp.MinLimit
+	cl.MaxLimit = tmp.MaxLimit
+	cl.BackoffRatio = tmp.BackoffRatio
+	cl.Tolerance = tmp.Tolerance
+	cl.Classes = tmp.Classes
+
+	if len(tmp.LatencyThreshold) > 0 {
+		cl.LatencyThreshold, err = str2duration.ParseDuration(tmp.LatencyThreshold)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -15,13 +15,15 @@ type Base struct {
 	IsDebugMode bool `yaml:"debugMode" json:"debug_mode"`
 	Profiling   Profiling
 	Monitoring  Monitoring
+	Tracing     Tracing
 	Single      *Single `yaml:"single,omitempty" json:"single,omitempty"`
 }
 
 type Client struct {
-	ClusterID string `yaml:"clusterId" json:"cluster_id" validate:"uuid"`
-	Name      string
-	Token     string `validate:"jwt"`
+	ClusterID   string `yaml:"clusterId" json:"cluster_id" validate:"uuid"`
+	Name        string
+	Token       string       `validate:"required_without=Credentials,omitempty,jwt"`
+	Credentials *Credentials `yaml:"credentials,omitempty" json:"credentials,omitempty"`
 }
 
 type Single struct {
@@ -234,6 +236,18 @@ type Profiling struct {
 	Port    uint16 `yaml:"port,omitempty" json:"port,omitempty" validate:"port_if_enabled"`
 }
 
+// Tracing configures the OpenTelemetry provider, SampleRatio is the share of the sampled root traces
+// and 0 disables the sampling of new traces, the sampled parents are followed always.
+type Tracing struct {
+	Enabled     bool
+	ServiceName string                `yaml:"serviceName,omitempty" json:"service_name,omitempty" validate:"required_if=Enabled true"`
+	Exporter    enums.TracingExporter `yaml:"exporter" json:"exporter"`
+	Endpoint    string                `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	Insecure    bool                  `yaml:"insecure" json:"insecure"`
+	FilePath    string                `yaml:"filePath,omitempty" json:"file_path,omitempty"`
+	SampleRatio float64               `yaml:"sampleRatio" json:"sample_ratio" validate:"gte=0,lte=1"`
+}
+
 type CronStr string
 
 type Informer struct {
@@ -247,16 +261,266 @@ type K8sCloudWatcher struct {
 }
 
 type GRPC struct {
-	Enabled       bool
-	UseReflection bool        `yaml:"useReflection" json:"use_reflection"`
-	Compression   Compression `yaml:"compression" json:"compression"`
-	Conn          *Connection `yaml:"connection" json:"connection" validate:"required"`
-	Keepalive     *Keepalive  `yaml:"keepalive" json:"keepalive"`
+	Enabled          bool
+	UseReflection    bool              `yaml:"useReflection" json:"use_reflection"`
+	Compression      Compression       `yaml:"compression" json:"compression"`
+	Conn             *Connection       `yaml:"connection" json:"connection" validate:"required"`
+	Keepalive        *Keepalive        `yaml:"keepalive" json:"keepalive"`
+	Retry            *Retry            `yaml:"retry,omitempty" json:"retry,omitempty"`
+	Deadlines        *Deadlines        `yaml:"deadlines,omitempty" json:"deadlines,omitempty"`
+	Logging          *Logging          `yaml:"logging,omitempty" json:"logging,omitempty"`
+	TLS              *TLS              `yaml:"tls,omitempty" json:"tls,omitempty"`
+	Shutdown         *Shutdown         `yaml:"shutdown,omitempty" json:"shutdown,omitempty"`
+	LoadBalancing    *LoadBalancing    `yaml:"loadBalancing,omitempty" json:"load_balancing,omitempty"`
+	Idempotency      *Idempotency      `yaml:"idempotency,omitempty" json:"idempotency,omitempty"`
+	ResponseCache    *ResponseCache    `yaml:"responseCache,omitempty" json:"response_cache,omitempty"`
+	TenantMetrics    *TenantMetrics    `yaml:"tenantMetrics,omitempty" json:"tenant_metrics,omitempty"`
+	FaultInjection   *FaultInjection   `yaml:"faultInjection,omitempty" json:"fault_injection,omitempty"`
+	ConcurrencyLimit *ConcurrencyLimit `yaml:"concurrencyLimit,omitempty" json:"concurrency_limit,omitempty"`
+	Authz            *Authz            `yaml:"authz,omitempty" json:"authz,omitempty"`
 }
 
 type Compression struct {
-	Enabled bool                  `yaml:"enabled" json:"enabled"`
-	Type    enums.CompressionType `yaml:"type" json:"type"`
+	Enabled             bool                             `yaml:"enabled" json:"enabled"`
+	Type                enums.CompressionType            `yaml:"type" json:"type"`
+	Level               int                              `yaml:"level" json:"level"`
+	MinSize             uint                             `yaml:"minSize,omitempty" json:"min_size,omitempty"`
+	Methods             map[string]enums.CompressionType `yaml:"methods,omitempty" json:"methods,omitempty"`
+	AcceptedEncodings   []enums.CompressionType          `yaml:"acceptedEncodings,omitempty" json:"accepted_encodings,omitempty"`
+	RejectedEncodings   []enums.CompressionType          `yaml:"rejectedEncodings,omitempty" json:"rejected_encodings,omitempty"`
+	MaxDecompressedSize uint                             `yaml:"maxDecompressedSize,omitempty" json:"max_decompressed_size,omitempty"`
+	Dictionaries        []string                         `yaml:"dictionaries,omitempty" json:"dictionaries,omitempty" validate:"omitempty,dive,file"`
+}
+
+// TypeFor returns the compression type of the client calls of the full gRPC method name,
+// the Methods keys can be "/package.Service/Method", "package.Service/Method" or "package.Service".
+func (c *Compression) TypeFor(fullMethod string) enums.CompressionType {
+	if c == nil || !c.Enabled {
+		return enums.None
+	}
+
+	for _, key := range GrpcMethodKeys(fullMethod) {
+		if t, ok := c.Methods[key]; ok {
+			return t
+		}
+	}
+
+	return c.Type
+}
+
+// Accepts reports whether the server accepts the requests compressed with the compression type,
+// the rejected encodings take precedence over the accepted ones and all of them are accepted by default.
+func (c *Compression) Accepts(t enums.CompressionType) bool {
+	if c == nil {
+		return true
+	}
+
+	for _, rejected := range c.RejectedEncodings {
+		if rejected == t {
+			return false
+		}
+	}
+
+	if len(c.AcceptedEncodings) == 0 {
+		return true
+	}
+
+	for _, accepted := range c.AcceptedEncodings {
+		if accepted == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Types returns all the compression types used by the client calls.
+func (c *Compression) Types() (out []enums.CompressionType) {
+	seen := map[enums.CompressionType]bool{enums.None: true}
+
+	if !seen[c.Type] {
+		seen[c.Type] = true
+		out = append(out, c.Type)
+	}
+
+	for _, t := range c.Methods {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+
+	return out
+}
+
+func (c *Compression) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		Enabled             bool                             `yaml:"enabled" json:"enabled"`
+		Type                enums.CompressionType            `yaml:"type" json:"type"`
+		Level               int                              `yaml:"level" json:"level"`
+		MinSize             string                           `yaml:"minSize,omitempty" json:"min_size,omitempty"`
+		Methods             map[string]enums.CompressionType `yaml:"methods,omitempty" json:"methods,omitempty"`
+		AcceptedEncodings   []enums.CompressionType          `yaml:"acceptedEncodings,omitempty" json:"accepted_encodings,omitempty"`
+		RejectedEncodings   []enums.CompressionType          `yaml:"rejectedEncodings,omitempty" json:"rejected_encodings,omitempty"`
+		MaxDecompressedSize string                           `yaml:"maxDecompressedSize,omitempty" json:"max_decompressed_size,omitempty"`
+		Dictionaries        []string                         `yaml:"dictionaries,omitempty" json:"dictionaries,omitempty"`
+	}
+
+	if c == nil {
+		*c = Compression{}
+	}
+
+	return json.Marshal(alias{
+		Enabled:             c.Enabled,
+		Type:                c.Type,
+		Level:               c.Level,
+		MinSize:             sizeToStr(c.MinSize),
+		Methods:             c.Methods,
+		AcceptedEncodings:   c.AcceptedEncodings,
+		RejectedEncodings:   c.RejectedEncodings,
+		MaxDecompressedSize: sizeToStr(c.MaxDecompressedSize),
+		Dictionaries:        c.Dictionaries,
+	})
+}
+
+func (c *Compression) UnmarshalJSON(data []byte) (err error) {
+	type alias struct {
+		Enabled             bool                             `yaml:"enabled" json:"enabled"`
+		Type                enums.CompressionType            `yaml:"type" json:"type"`
+		Level               int                              `yaml:"level" json:"level"`
+		MinSize             string                           `yaml:"minSize,omitempty" json:"min_size,omitempty"`
+		Methods             map[string]enums.CompressionType `yaml:"methods,omitempty" json:"methods,omitempty"`
+		AcceptedEncodings   []enums.CompressionType          `yaml:"acceptedEncodings,omitempty" json:"accepted_encodings,omitempty"`
+		RejectedEncodings   []enums.CompressionType          `yaml:"rejectedEncodings,omitempty" json:"rejected_encodings,omitempty"`
+		MaxDecompressedSize string                           `yaml:"maxDecompressedSize,omitempty" json:"max_decompressed_size,omitempty"`
+		Dictionaries        []string                         `yaml:"dictionaries,omitempty" json:"dictionaries,omitempty"`
+	}
+
+	var tmp alias
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if c == nil {
+		*c = Compression{}
+	}
+
+	c.Enabled = tmp.Enabled
+	c.Type = tmp.Type
+	c.Level = tmp.Level
+	c.Methods = tmp.Methods
+	c.AcceptedEncodings = tmp.AcceptedEncodings
+	c.RejectedEncodings = tmp.RejectedEncodings
+	c.Dictionaries = tmp.Dictionaries
+
+	if len(tmp.MinSize) > 0 {
+		var size int64
+		if size, err = tc.RAMInBytes(tmp.MinSize); err != nil {
+			return err
+		}
+
+		c.MinSize = uint(size)
+	}
+
+	if len(tmp.MaxDecompressedSize) > 0 {
+		var size int64
+		if size, err = tc.RAMInBytes(tmp.MaxDecompressedSize); err != nil {
+			return err
+		}
+
+		c.MaxDecompressedSize = uint(size)
+	}
+
+	return nil
+}
+
+func (c *Compression) MarshalYAML() (interface{}, error) {
+	type alias struct {
+		Enabled             bool                             `yaml:"enabled" json:"enabled"`
+		Type                enums.CompressionType            `yaml:"type" json:"type"`
+		Level               int                              `yaml:"level" json:"level"`
+		MinSize             string                           `yaml:"minSize,omitempty" json:"min_size,omitempty"`
+		Methods             map[string]enums.CompressionType `yaml:"methods,omitempty" json:"methods,omitempty"`
+		AcceptedEncodings   []enums.CompressionType          `yaml:"acceptedEncodings,omitempty" json:"accepted_encodings,omitempty"`
+		RejectedEncodings   []enums.CompressionType          `yaml:"rejectedEncodings,omitempty" json:"rejected_encodings,omitempty"`
+		MaxDecompressedSize string                           `yaml:"maxDecompressedSize,omitempty" json:"max_decompressed_size,omitempty"`
+		Dictionaries        []string                         `yaml:"dictionaries,omitempty" json:"dictionaries,omitempty"`
+	}
+
+	if c == nil {
+		*c = Compression{}
+	}
+
+	return alias{
+		Enabled:             c.Enabled,
+		Type:                c.Type,
+		Level:               c.Level,
+		MinSize:             sizeToStr(c.MinSize),
+		Methods:             c.Methods,
+		AcceptedEncodings:   c.AcceptedEncodings,
+		RejectedEncodings:   c.RejectedEncodings,
+		MaxDecompressedSize: sizeToStr(c.MaxDecompressedSize),
+		Dictionaries:        c.Dictionaries,
+	}, nil
+}
+
+func (c *Compression) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	type alias struct {
+		Enabled             bool                             `yaml:"enabled" json:"enabled"`
+		Type                enums.CompressionType            `yaml:"type" json:"type"`
+		Level               int                              `yaml:"level" json:"level"`
+		MinSize             string                           `yaml:"minSize,omitempty" json:"min_size,omitempty"`
+		Methods             map[string]enums.CompressionType `yaml:"methods,omitempty" json:"methods,omitempty"`
+		AcceptedEncodings   []enums.CompressionType          `yaml:"acceptedEncodings,omitempty" json:"accepted_encodings,omitempty"`
+		RejectedEncodings   []enums.CompressionType          `yaml:"rejectedEncodings,omitempty" json:"rejected_encodings,omitempty"`
+		MaxDecompressedSize string                           `yaml:"maxDecompressedSize,omitempty" json:"max_decompressed_size,omitempty"`
+		Dictionaries        []string                         `yaml:"dictionaries,omitempty" json:"dictionaries,omitempty"`
+	}
+
+	var tmp alias
+	if err = unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	if c == nil {
+		*c = Compression{}
+	}
+
+	c.Enabled = tmp.Enabled
+	c.Type = tmp.Type
+	c.Level = tmp.Level
+	c.Methods = tmp.Methods
+	c.AcceptedEncodings = tmp.AcceptedEncodings
+	c.RejectedEncodings = tmp.RejectedEncodings
+	c.Dictionaries = tmp.Dictionaries
+
+	if len(tmp.MinSize) > 0 {
+		var size int64
+		if size, err = tc.RAMInBytes(tmp.MinSize); err != nil {
+			return err
+		}
+
+		c.MinSize = uint(size)
+	}
+
+	if len(tmp.MaxDecompressedSize) > 0 {
+		var size int64
+		if size, err = tc.RAMInBytes(tmp.MaxDecompressedSize); err != nil {
+			return err
+		}
+
+		c.MaxDecompressedSize = uint(size)
+	}
+
+	return nil
+}
+
+func sizeToStr(size uint) string {
+	if size == 0 {
+		return ""
+	}
+
+	return tc.BytesSize(float64(size))
 }
 
 type Connection struct {
@@ -427,16 +691,22 @@ func (c *Connection) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 type Keepalive struct {
-	Time              time.Duration      `yaml:"time" json:"time" validate:"required,gt=0"`
-	Timeout           time.Duration      `yaml:"timeout" json:"timeout" validate:"required,gt=0"`
-	EnforcementPolicy *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
+	Time                  time.Duration      `yaml:"time" json:"time" validate:"required,gt=0"`
+	Timeout               time.Duration      `yaml:"timeout" json:"timeout" validate:"required,gt=0"`
+	MaxConnectionIdle     time.Duration      `yaml:"maxConnectionIdle,omitempty" json:"max_connection_idle,omitempty" validate:"gte=0"`
+	MaxConnectionAge      time.Duration      `yaml:"maxConnectionAge,omitempty" json:"max_connection_age,omitempty" validate:"gte=0"`
+	MaxConnectionAgeGrace time.Duration      `yaml:"maxConnectionAgeGrace,omitempty" json:"max_connection_age_grace,omitempty" validate:"gte=0"`
+	EnforcementPolicy     *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
 }
 
 func (ka *Keepalive) MarshalJSON() ([]byte, error) {
 	type alias struct {
-		Time              string             `yaml:"time" json:"time"`
-		Timeout           string             `yaml:"timeout" json:"timeout"`
-		EnforcementPolicy *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
+		Time                  string             `yaml:"time" json:"time"`
+		Timeout               string             `yaml:"timeout" json:"timeout"`
+		MaxConnectionIdle     string             `yaml:"maxConnectionIdle,omitempty" json:"max_connection_idle,omitempty"`
+		MaxConnectionAge      string             `yaml:"maxConnectionAge,omitempty" json:"max_connection_age,omitempty"`
+		MaxConnectionAgeGrace string             `yaml:"maxConnectionAgeGrace,omitempty" json:"max_connection_age_grace,omitempty"`
+		EnforcementPolicy     *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
 	}
 
 	if ka == nil {
@@ -444,17 +714,23 @@ func (ka *Keepalive) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(alias{
-		Time:              ConvertDurationToStr(ka.Time),
-		Timeout:           ConvertDurationToStr(ka.Timeout),
-		EnforcementPolicy: ka.EnforcementPolicy,
+		Time:                  ConvertDurationToStr(ka.Time),
+		Timeout:               ConvertDurationToStr(ka.Timeout),
+		MaxConnectionIdle:     OptionalHumanDuration(ka.MaxConnectionIdle),
+		MaxConnectionAge:      OptionalHumanDuration(ka.MaxConnectionAge),
+		MaxConnectionAgeGrace: OptionalHumanDuration(ka.MaxConnectionAgeGrace),
+		EnforcementPolicy:     ka.EnforcementPolicy,
 	})
 }
 
 func (ka *Keepalive) UnmarshalJSON(data []byte) (err error) {
 	type alias struct {
-		Time              string             `yaml:"time" json:"time"`
-		Timeout           string             `yaml:"timeout" json:"timeout"`
-		EnforcementPolicy *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
+		Time                  string             `yaml:"time" json:"time"`
+		Timeout               string             `yaml:"timeout" json:"timeout"`
+		MaxConnectionIdle     string             `yaml:"maxConnectionIdle,omitempty" json:"max_connection_idle,omitempty"`
+		MaxConnectionAge      string             `yaml:"maxConnectionAge,omitempty" json:"max_connection_age,omitempty"`
+		MaxConnectionAgeGrace string             `yaml:"maxConnectionAgeGrace,omitempty" json:"max_connection_age_grace,omitempty"`
+		EnforcementPolicy     *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
 	}
 	var tmp alias
 	if err = json.Unmarshal(data, &tmp); err != nil {
@@ -475,6 +751,21 @@ func (ka *Keepalive) UnmarshalJSON(data []byte) (err error) {
 		return err
 	}
 
+	for _, d := range []struct {
+		value string
+		out   *time.Duration
+	}{
+		{tmp.MaxConnectionIdle, &ka.MaxConnectionIdle},
+		{tmp.MaxConnectionAge, &ka.MaxConnectionAge},
+		{tmp.MaxConnectionAgeGrace, &ka.MaxConnectionAgeGrace},
+	} {
+		if len(d.value) > 0 {
+			if *d.out, err = str2duration.ParseDuration(d.value); err != nil {
+				return err
+			}
+		}
+	}
+
 	ka.EnforcementPolicy = tmp.EnforcementPolicy
 
 	return nil
@@ -482,9 +773,12 @@ func (ka *Keepalive) UnmarshalJSON(data []byte) (err error) {
 
 func (ka *Keepalive) MarshalYAML() (interface{}, error) {
 	type alias struct {
-		Time              string             `yaml:"time" json:"time"`
-		Timeout           string             `yaml:"timeout" json:"timeout"`
-		EnforcementPolicy *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
+		Time                  string             `yaml:"time" json:"time"`
+		Timeout               string             `yaml:"timeout" json:"timeout"`
+		MaxConnectionIdle     string             `yaml:"maxConnectionIdle,omitempty" json:"max_connection_idle,omitempty"`
+		MaxConnectionAge      string             `yaml:"maxConnectionAge,omitempty" json:"max_connection_age,omitempty"`
+		MaxConnectionAgeGrace string             `yaml:"maxConnectionAgeGrace,omitempty" json:"max_connection_age_grace,omitempty"`
+		EnforcementPolicy     *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
 	}
 
 	if ka == nil {
@@ -492,17 +786,23 @@ func (ka *Keepalive) MarshalYAML() (interface{}, error) {
 	}
 
 	return alias{
-		Time:              ConvertDurationToStr(ka.Time),
-		Timeout:           ConvertDurationToStr(ka.Timeout),
-		EnforcementPolicy: ka.EnforcementPolicy,
+		Time:                  ConvertDurationToStr(ka.Time),
+		Timeout:               ConvertDurationToStr(ka.Timeout),
+		MaxConnectionIdle:     OptionalHumanDuration(ka.MaxConnectionIdle),
+		MaxConnectionAge:      OptionalHumanDuration(ka.MaxConnectionAge),
+		MaxConnectionAgeGrace: OptionalHumanDuration(ka.MaxConnectionAgeGrace),
+		EnforcementPolicy:     ka.EnforcementPolicy,
 	}, nil
 }
 
 func (ka *Keepalive) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	type alias struct {
-		Time              string             `yaml:"time" json:"time"`
-		Timeout           string             `yaml:"timeout" json:"timeout"`
-		EnforcementPolicy *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
+		Time                  string             `yaml:"time" json:"time"`
+		Timeout               string             `yaml:"timeout" json:"timeout"`
+		MaxConnectionIdle     string             `yaml:"maxConnectionIdle,omitempty" json:"max_connection_idle,omitempty"`
+		MaxConnectionAge      string             `yaml:"maxConnectionAge,omitempty" json:"max_connection_age,omitempty"`
+		MaxConnectionAgeGrace string             `yaml:"maxConnectionAgeGrace,omitempty" json:"max_connection_age_grace,omitempty"`
+		EnforcementPolicy     *EnforcementPolicy `yaml:"enforcementPolicy" json:"enforcement_policy"`
 	}
 	var tmp alias
 	err := unmarshal(&tmp)
@@ -524,6 +824,21 @@ func (ka *Keepalive) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	for _, d := range []struct {
+		value string
+		out   *time.Duration
+	}{
+		{tmp.MaxConnectionIdle, &ka.MaxConnectionIdle},
+		{tmp.MaxConnectionAge, &ka.MaxConnectionAge},
+		{tmp.MaxConnectionAgeGrace, &ka.MaxConnectionAgeGrace},
+	} {
+		if len(d.value) > 0 {
+			if *d.out, err = str2duration.ParseDuration(d.value); err != nil {
+				return err
+			}
+		}
+	}
+
 	ka.EnforcementPolicy = tmp.EnforcementPolicy
 
 	return nil
@@ -848,6 +1163,8 @@ type HTTPTransport struct {
 	ReadTimeout         time.Duration     `yaml:"readTimeout" json:"read_timeout" validate:"required,gt=0"`
 	WriteTimeout        time.Duration     `yaml:"writeTimeout" json:"write_timeout" validate:"required,gt=0"`
 	NetTransport        *NetHTTPTransport `yaml:"netTransport,omitempty" json:"net_transport,omitempty" validate:"omitempty"`
+	CircuitBreaker      *CircuitBreaker   `yaml:"circuitBreaker,omitempty" json:"circuit_breaker,omitempty" validate:"omitempty"`
+	FaultInjection      *FaultInjection   `yaml:"faultInjection,omitempty" json:"fault_injection,omitempty" validate:"omitempty"`
 }
 
 func (t *HTTPTransport) GetTransportConfigs() *HTTPTransport {
@@ -860,6 +1177,8 @@ func (t *HTTPTransport) MarshalJSON() ([]byte, error) {
 		ReadTimeout         string            `yaml:"readTimeout" json:"read_timeout"`
 		WriteTimeout        string            `yaml:"writeTimeout" json:"write_timeout"`
 		NetTransport        *NetHTTPTransport `yaml:"netTransport,omitempty" json:"net_transport,omitempty"`
+		CircuitBreaker      *CircuitBreaker   `yaml:"circuitBreaker,omitempty" json:"circuit_breaker,omitempty"`
+		FaultInjection      *FaultInjection   `yaml:"faultInjection,omitempty" json:"fault_injection,omitempty"`
 	}
 
 	if t == nil {
@@ -871,6 +1190,8 @@ func (t *HTTPTransport) MarshalJSON() ([]byte, error) {
 		ReadTimeout:         HumanDuration(t.ReadTimeout),
 		WriteTimeout:        HumanDuration(t.WriteTimeout),
 		NetTransport:        t.NetTransport,
+		CircuitBreaker:      t.CircuitBreaker,
+		FaultInjection:      t.FaultInjection,
 	})
 }
 
@@ -880,6 +1201,8 @@ func (t *HTTPTransport) UnmarshalJSON(data []byte) (err error) {
 		ReadTimeout         string            `yaml:"readTimeout" json:"read_timeout"`
 		WriteTimeout        string            `yaml:"writeTimeout" json:"write_timeout"`
 		NetTransport        *NetHTTPTransport `yaml:"netTransport,omitempty" json:"net_transport,omitempty"`
+		CircuitBreaker      *CircuitBreaker   `yaml:"circuitBreaker,omitempty" json:"circuit_breaker,omitempty"`
+		FaultInjection      *FaultInjection   `yaml:"faultInjection,omitempty" json:"fault_injection,omitempty"`
 	}
 	var tmp alias
 	if err = json.Unmarshal(data, &tmp); err != nil {
@@ -906,6 +1229,8 @@ func (t *HTTPTransport) UnmarshalJSON(data []byte) (err error) {
 	}
 
 	t.NetTransport = tmp.NetTransport
+	t.CircuitBreaker = tmp.CircuitBreaker
+	t.FaultInjection = tmp.FaultInjection
 
 	return nil
 }
@@ -916,6 +1241,8 @@ func (t *HTTPTransport) MarshalYAML() (interface{}, error) {
 		ReadTimeout         string            `yaml:"readTimeout" json:"read_timeout"`
 		WriteTimeout        string            `yaml:"writeTimeout" json:"write_timeout"`
 		NetTransport        *NetHTTPTransport `yaml:"netTransport,omitempty" json:"net_transport,omitempty"`
+		CircuitBreaker      *CircuitBreaker   `yaml:"circuitBreaker,omitempty" json:"circuit_breaker,omitempty"`
+		FaultInjection      *FaultInjection   `yaml:"faultInjection,omitempty" json:"fault_injection,omitempty"`
 	}
 
 	if t == nil {
@@ -927,6 +1254,8 @@ func (t *HTTPTransport) MarshalYAML() (interface{}, error) {
 		ReadTimeout:         HumanDuration(t.ReadTimeout),
 		WriteTimeout:        HumanDuration(t.WriteTimeout),
 		NetTransport:        t.NetTransport,
+		CircuitBreaker:      t.CircuitBreaker,
+		FaultInjection:      t.FaultInjection,
 	}, nil
 }
 
@@ -936,6 +1265,8 @@ func (t *HTTPTransport) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		ReadTimeout         string            `yaml:"readTimeout" json:"read_timeout"`
 		WriteTimeout        string            `yaml:"writeTimeout" json:"write_timeout"`
 		NetTransport        *NetHTTPTransport `yaml:"netTransport,omitempty" json:"net_transport,omitempty"`
+		CircuitBreaker      *CircuitBreaker   `yaml:"circuitBreaker,omitempty" json:"circuit_breaker,omitempty"`
+		FaultInjection      *FaultInjection   `yaml:"faultInjection,omitempty" json:"fault_injection,omitempty"`
 	}
 	var tmp alias
 	err := unmarshal(&tmp)
@@ -963,6 +1294,8 @@ func (t *HTTPTransport) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	}
 
 	t.NetTransport = tmp.NetTransport
+	t.CircuitBreaker = tmp.CircuitBreaker
+	t.FaultInjection = tmp.FaultInjection
 
 	return nil
 }
@@ -0,0 +1,64 @@
+package configs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestOptionalDurations(t *testing.T) {
+	var cases = []struct {
+		name     string
+		value    interface{}
+		wantJSON string
+		wantYAML string
+	}{
+		{
+			name:     "zero keepalive durations are omitted",
+			value:    &Keepalive{Time: 30 * time.Second, Timeout: time.Second},
+			wantJSON: `{"time":"30s","timeout":"1s","enforcement_policy":null}`,
+			wantYAML: "time: 30s\ntimeout: 1s\nenforcementPolicy: null\n",
+		},
+		{
+			name:     "keepalive durations",
+			value:    &Keepalive{Time: 30 * time.Second, Timeout: time.Second, MaxConnectionAge: time.Hour},
+			wantJSON: `{"time":"30s","timeout":"1s","max_connection_age":"1h","enforcement_policy":null}`,
+			wantYAML: "time: 30s\ntimeout: 1s\nmaxConnectionAge: 1h\nenforcementPolicy: null\n",
+		},
+		{
+			name:     "zero drain period is omitted",
+			value:    &Shutdown{Timeout: 10 * time.Second},
+			wantJSON: `{"timeout":"10s"}`,
+			wantYAML: "timeout: 10s\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := json.Marshal(c.value)
+			require.NoError(t, err)
+			assert.Equal(t, c.wantJSON, string(data))
+
+			data, err = yaml.Marshal(c.value)
+			require.NoError(t, err)
+			assert.Equal(t, c.wantYAML, string(data))
+		})
+	}
+}
+
+func TestFaultAdminTokenIsNotSerialized(t *testing.T) {
+	conf := &FaultInjection{Enabled: true, AdminToken: "admin-secret", AdminTokenFile: "/run/secrets/fault-admin"}
+
+	data, err := json.Marshal(conf)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "admin-secret")
+	assert.Contains(t, string(data), "/run/secrets/fault-admin")
+
+	data, err = yaml.Marshal(conf)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "admin-secret")
+}
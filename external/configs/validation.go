@@ -23,6 +23,8 @@ const (
 	UUIDIfNotEmptyTag       = "uuid_if_not_empty"
 	JWTIfNotEmptyTag        = "jwt_if_not_empty"
 	EmailIfNotEmpty         = "email_if_not_empty"
+	GRPCCodeTag             = "grpc_code"
+	TLSVersionTag           = "tls_version"
 )
 
 var (
@@ -90,6 +92,14 @@ func RegisterCustomValidationsTags(ctx context.Context, validator *validator.Val
 		return err
 	}
 
+	if err = validator.RegisterValidation(GRPCCodeTag, ValidateGRPCCode); err != nil {
+		return err
+	}
+
+	if err = validator.RegisterValidation(TLSVersionTag, ValidateTLSVersion); err != nil {
+		return err
+	}
+
 	return err
 }
 
@@ -292,3 +302,15 @@ func ValidateEmailIfNotEmpty(validatorMain *validator.Validate) func(level valid
 		return true
 	}
 }
+
+// ValidateGRPCCode implements validator.Func for validate gRPC status code names (e.g. "UNAVAILABLE")
+func ValidateGRPCCode(fl validator.FieldLevel) bool {
+	_, err := ParseGrpcCode(fl.Field().String())
+	return err == nil
+}
+
+// ValidateTLSVersion implements validator.Func
+func ValidateTLSVersion(fl validator.FieldLevel) bool {
+	_, err := ParseTLSVersion(fl.Field().String())
+	return err == nil
+}
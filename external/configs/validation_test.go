@@ -146,3 +146,62 @@ func TestValidateRequiredIfNotEmpty(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRetryPolicy(t *testing.T) {
+	var cases = []struct {
+		name    string
+		policy  RetryPolicy
+		wantTag string
+	}{
+		{
+			name:   "backoffs are omitted",
+			policy: RetryPolicy{MaxAttempts: 3, RetryableCodes: []string{"Unavailable"}},
+		},
+		{
+			name:   "backoffs",
+			policy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second},
+		},
+		{
+			name:    "max backoff less than initial backoff",
+			policy:  RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Millisecond},
+			wantTag: "gtefield",
+		},
+		{
+			name:    "negative initial backoff",
+			policy:  RetryPolicy{MaxAttempts: 3, InitialBackoff: -time.Second},
+			wantTag: "gt",
+		},
+	}
+
+	testValidator := validator.New()
+	assert.NoError(t, RegisterCustomValidationsTags(context.Background(), testValidator, nil, nil))
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			err := testValidator.Struct(c.policy)
+			if len(c.wantTag) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			valErr, ok := err.(validator.ValidationErrors)
+			if assert.True(t, ok, err) && assert.Len(t, valErr, 1) {
+				assert.Equal(t, c.wantTag, valErr[0].Tag())
+			}
+		})
+	}
+}
+
+func TestValidateFaultRule(t *testing.T) {
+	testValidator := validator.New()
+	assert.NoError(t, RegisterCustomValidationsTags(context.Background(), testValidator, nil, nil))
+
+	assert.NoError(t, testValidator.Struct(FaultRule{Percentage: 10, Code: "UNAVAILABLE"}))
+	assert.NoError(t, testValidator.Struct(FaultRule{Percentage: 10, Drop: true}))
+
+	err := testValidator.Struct(FaultRule{Percentage: 10, Code: "UNAVAILABE"})
+	if valErr, ok := err.(validator.ValidationErrors); assert.True(t, ok, err) && assert.Len(t, valErr, 1) {
+		assert.Equal(t, GRPCCodeTag, valErr[0].Tag())
+	}
+}
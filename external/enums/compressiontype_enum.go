@@ -18,11 +18,14 @@ func _() {
 	_ = x[None-0]
 	_ = x[Gzip-1]
 	_ = x[Zstd-2]
+	_ = x[Snappy-3]
+	_ = x[LZ4-4]
+	_ = x[Brotli-5]
 }
 
-const _CompressionType_name = "nonegzipzstd"
+const _CompressionType_name = "nonegzipzstdsnappylz4brotli"
 
-var _CompressionType_index = [...]uint8{0, 4, 8, 12}
+var _CompressionType_index = [...]uint8{0, 4, 8, 12, 18, 21, 27}
 
 func _() {
 	var _nil_CompressionType_value = func() (val CompressionType) { return }()
@@ -45,12 +48,15 @@ func (i CompressionType) New() *CompressionType {
 	return &clone
 }
 
-var _CompressionType_values = []CompressionType{0, 1, 2}
+var _CompressionType_values = []CompressionType{0, 1, 2, 3, 4, 5}
 
 var _CompressionType_name_to_values = map[string]CompressionType{
-	_CompressionType_name[0:4]:  0,
-	_CompressionType_name[4:8]:  1,
-	_CompressionType_name[8:12]: 2,
+	_CompressionType_name[0:4]:   0,
+	_CompressionType_name[4:8]:   1,
+	_CompressionType_name[8:12]:  2,
+	_CompressionType_name[12:18]: 3,
+	_CompressionType_name[18:21]: 4,
+	_CompressionType_name[21:27]: 5,
 }
 
 // ParseCompressionTypeString retrieves an enum value from the enum constants string name.
@@ -1,6 +1,10 @@
 package enums
 
-import "github.com/dysnix/predictkube-proto/external/proto/enums"
+import (
+	"crypto/tls"
+
+	"github.com/dysnix/predictkube-proto/external/proto/enums"
+)
 
 //go:generate go-enum -type=CompressionType -transform=lower
 // CompressionType is an enumeration of GRPC traffic compression type values
@@ -10,6 +14,9 @@ const (
 	None CompressionType = iota // default compression type (if not use compression)
 	Gzip                        // gzip compression type
 	Zstd                        // zstd compression type
+	Snappy                      // snappy compression type
+	LZ4                         // lz4 compression type
+	Brotli                      // brotli compression type
 )
 
 //go:generate go-enum -type=SSLMode -transform=lower
@@ -92,3 +99,67 @@ const (
 	NetHTTP  TransportType = iota // NetHTTP transport from net/http package
 	FastHTTP                      // FastHTTP transport from github.com/valyala/fasthttp package
 )
+
+//go:generate go-enum -type=TracingExporter -transform=lower
+// TracingExporter is a type of OpenTelemetry spans exporter
+type TracingExporter int
+
+const (
+	OTLP   TracingExporter = iota // OTLP gRPC exporter to the collector
+	Stdout                        // Stdout exporter (for tests and local debugging)
+	File                          // File exporter (for tests and local debugging)
+)
+
+//go:generate go-enum -type=ClientAuthType
+// ClientAuthType is a TLS client certificate authentication policy of the server
+type ClientAuthType int
+
+const (
+	NoClientCert               ClientAuthType = iota // NoClientCert client certificate isn't requested
+	RequestClientCert                                // RequestClientCert client certificate is requested but not required
+	RequireAnyClientCert                             // RequireAnyClientCert client certificate is required but not verified
+	VerifyClientCertIfGiven                          // VerifyClientCertIfGiven client certificate is verified if given
+	RequireAndVerifyClientCert                       // RequireAndVerifyClientCert client certificate is required and verified (mTLS)
+)
+
+var (
+	_clientAuthTypeToTLS = map[ClientAuthType]tls.ClientAuthType{
+		NoClientCert:               tls.NoClientCert,
+		RequestClientCert:          tls.RequestClientCert,
+		RequireAnyClientCert:       tls.RequireAnyClientCert,
+		VerifyClientCertIfGiven:    tls.VerifyClientCertIfGiven,
+		RequireAndVerifyClientCert: tls.RequireAndVerifyClientCert,
+	}
+)
+
+func (i ClientAuthType) AdaptToTLS() tls.ClientAuthType {
+	return _clientAuthTypeToTLS[i]
+}
+
+//go:generate go-enum -type=ResolverType -transform=lower
+// ResolverType is a type of GRPC client name resolver
+type ResolverType int
+
+const (
+	DNS    ResolverType = iota // DNS resolver of all the host addresses
+	Static                     // Static resolver of the configured endpoints list
+)
+
+//go:generate go-enum -type=BalancingPolicy -transform=snake
+// BalancingPolicy is a GRPC client load balancing policy
+type BalancingPolicy int
+
+const (
+	PickFirst  BalancingPolicy = iota // PickFirst policy uses the first reachable address
+	RoundRobin                        // RoundRobin policy spreads the calls across all the addresses
+)
+
+//go:generate go-enum -type=LimiterMode -transform=snake
+// LimiterMode is a mode of the server concurrency limit
+type LimiterMode int
+
+const (
+	StaticLimit LimiterMode = iota // StaticLimit keeps the configured limit
+	AIMD                           // AIMD increases the limit additively and decreases it multiplicatively on the slow or failed calls
+	Gradient                       // Gradient scales the limit by the ratio of the minimal latency to the current one
+)
@@ -0,0 +1,297 @@
+// Package fault injects the latency, the errors and the dropped responses into the gRPC calls
+// and the HTTP requests to check the behaviour of the clients against the slow or failing backends.
+package fault
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/app_errors"
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+type Side string
+
+const (
+	ClientSide Side = "client"
+	ServerSide Side = "server"
+	HTTPSide   Side = "http"
+)
+
+const (
+	latencyFault = "latency"
+	errorFault   = "error"
+	dropFault    = "drop"
+
+	anyMethod = "*"
+)
+
+var (
+	injectors   = make(map[string]*sharedInjector)
+	injectorsMu sync.Mutex
+)
+
+// sharedInjector is the injector of the configs name with the configs joined into it.
+type sharedInjector struct {
+	injector *Injector
+	confs    map[*configs.FaultInjection]bool
+}
+
+// Fault is the fault injected into a call, the call is delayed by the Latency
+// and then fails with the Code or the HTTPStatus, or its response is dropped.
+type Fault struct {
+	Latency    time.Duration
+	Code       codes.Code
+	HTTPStatus int
+	Drop       bool
+}
+
+// Err returns the status error of the fault code or nil.
+func (f *Fault) Err() error {
+	if f.Code == codes.OK {
+		return nil
+	}
+
+	return status.Errorf(f.Code, "fault injected: %s", f.Code)
+}
+
+// Delay waits for the latency of the fault, it returns the context error once the context is done.
+func (f *Fault) Delay(ctx context.Context) error {
+	if f.Latency <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(f.Latency)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type rule struct {
+	conf configs.FaultRule
+	code codes.Code
+}
+
+func (r *rule) match(side Side, method, clusterID string) (pattern string, ok bool) {
+	if len(r.conf.Clusters) > 0 && !contains(r.conf.Clusters, clusterID) {
+		return "", false
+	}
+
+	if len(r.conf.Methods) == 0 {
+		return anyMethod, true
+	}
+
+	for _, pattern = range r.conf.Methods {
+		if side == HTTPSide {
+			if strings.HasPrefix(method, pattern) {
+				return pattern, true
+			}
+
+			continue
+		}
+
+		if contains(configs.GrpcMethodKeys(method), pattern) {
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
+
+// Injector picks the faults of the calls by the rules, the rules and the active state can be changed at runtime.
+type Injector struct {
+	mu     sync.RWMutex
+	active bool
+	rules  []rule
+	rand   func() float64
+}
+
+// New returns the injector of the configs.
+func New(conf *configs.FaultInjection) (*Injector, error) {
+	registerMetrics()
+
+	i := &Injector{rand: rand.Float64}
+	if conf == nil {
+		return i, nil
+	}
+
+	if err := i.SetRules(conf.Rules); err != nil {
+		return nil, err
+	}
+
+	i.SetActive(conf.Active)
+
+	return i, nil
+}
+
+// For returns the shared injector of the configs name, so the gRPC client, the server, the HTTP transport
+// and the admin endpoint of the configs with the same name are toggled together. The rules of the configs
+// are joined in the order of the calls, so the rules without the methods apply to all of them, and the injector
+// is active when any of the configs is active.
+func For(conf *configs.FaultInjection) (*Injector, error) {
+	injectorsMu.Lock()
+	defer injectorsMu.Unlock()
+
+	shared, ok := injectors[conf.Name]
+	if !ok {
+		i, err := New(conf)
+		if err != nil {
+			return nil, err
+		}
+
+		injectors[conf.Name] = &sharedInjector{injector: i, confs: map[*configs.FaultInjection]bool{conf: true}}
+
+		return i, nil
+	}
+
+	if !shared.confs[conf] {
+		if err := shared.injector.SetRules(append(shared.injector.Rules(), conf.Rules...)); err != nil {
+			return nil, err
+		}
+
+		if conf.Active {
+			shared.injector.SetActive(true)
+		}
+
+		shared.confs[conf] = true
+	}
+
+	return shared.injector, nil
+}
+
+func (i *Injector) Active() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.active
+}
+
+func (i *Injector) SetActive(active bool) {
+	i.mu.Lock()
+	i.active = active
+	i.mu.Unlock()
+
+	activeGauge.Set(boolToFloat(active))
+}
+
+func (i *Injector) Rules() []configs.FaultRule {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	out := make([]configs.FaultRule, 0, len(i.rules))
+	for _, r := range i.rules {
+		out = append(out, r.conf)
+	}
+
+	return out
+}
+
+// SetRules replaces the rules, the rules with the unknown codes are rejected.
+func (i *Injector) SetRules(rules []configs.FaultRule) error {
+	parsed := make([]rule, 0, len(rules))
+
+	for idx, conf := range rules {
+		code := codes.OK
+		if len(conf.Code) > 0 {
+			var err error
+			if code, err = configs.ParseGrpcCode(conf.Code); err != nil {
+				return fmt.Errorf("fault rule %d: %w", idx, err)
+			}
+		}
+
+		if conf.Percentage < 0 || conf.Percentage > 100 {
+			return fmt.Errorf("fault rule %d: percentage %v is out of [0, 100]", idx, conf.Percentage)
+		}
+
+		parsed = append(parsed, rule{conf: conf, code: code})
+	}
+
+	i.mu.Lock()
+	i.rules = parsed
+	i.mu.Unlock()
+
+	return nil
+}
+
+// Pick returns the fault of the call or nil, the first rule matching the method and the cluster id is applied
+// to its percentage of the calls. The method is the full gRPC method name or the HTTP request path.
+func (i *Injector) Pick(side Side, method, clusterID string) *Fault {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if !i.active {
+		return nil
+	}
+
+	for _, r := range i.rules {
+		pattern, ok := r.match(side, method, clusterID)
+		if !ok {
+			continue
+		}
+
+		if i.rand()*100 >= r.conf.Percentage {
+			return nil
+		}
+
+		f := &Fault{
+			Latency:    r.conf.Latency,
+			Code:       r.code,
+			HTTPStatus: r.conf.HTTPStatus,
+			Drop:       r.conf.Drop,
+		}
+
+		if side == HTTPSide && f.HTTPStatus == 0 && f.Code != codes.OK {
+			f.HTTPStatus = app_errors.KindOfCode(f.Code).HTTPStatus()
+		}
+
+		f.record(side, pattern)
+
+		return f
+	}
+
+	return nil
+}
+
+func (f *Fault) record(side Side, pattern string) {
+	if f.Latency > 0 {
+		injectedTotal.WithLabelValues(string(side), pattern, latencyFault).Inc()
+	}
+
+	if f.Code != codes.OK || f.HTTPStatus != 0 {
+		injectedTotal.WithLabelValues(string(side), pattern, errorFault).Inc()
+	}
+
+	if f.Drop {
+		injectedTotal.WithLabelValues(string(side), pattern, dropFault).Inc()
+	}
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,132 @@
+package fault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+func TestInjectorPick(t *testing.T) {
+	i, err := New(&configs.FaultInjection{
+		Enabled: true,
+		Active:  true,
+		Rules: []configs.FaultRule{
+			{
+				Methods:    []string{"services.GatewaySaverService"},
+				Clusters:   []string{"bsc-1"},
+				Percentage: 50,
+				Latency:    time.Second,
+				Code:       "DeadlineExceeded",
+			},
+			{
+				Methods:    []string{"/api/v1/"},
+				Percentage: 100,
+				Code:       "UNAVAILABLE",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	var cases = []struct {
+		name      string
+		side      Side
+		method    string
+		clusterID string
+		roll      float64
+		want      *Fault
+	}{
+		{
+			name:      "rule of the service and the cluster",
+			side:      ClientSide,
+			method:    "/services.GatewaySaverService/GetMetricsOffset",
+			clusterID: "bsc-1",
+			roll:      0.49,
+			want:      &Fault{Latency: time.Second, Code: codes.DeadlineExceeded},
+		},
+		{
+			name:      "out of the percentage",
+			side:      ClientSide,
+			method:    "/services.GatewaySaverService/GetMetricsOffset",
+			clusterID: "bsc-1",
+			roll:      0.5,
+		},
+		{
+			name:      "other cluster",
+			side:      ServerSide,
+			method:    "/services.GatewaySaverService/GetMetricsOffset",
+			clusterID: "eth-1",
+		},
+		{
+			name:   "http path prefix with the status of the code",
+			side:   HTTPSide,
+			method: "/api/v1/metrics",
+			roll:   0.99,
+			want:   &Fault{Code: codes.Unavailable, HTTPStatus: 503},
+		},
+		{
+			name:   "gRPC methods aren't matched by the prefix",
+			side:   ServerSide,
+			method: "/api/v1/metrics",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			i.rand = func() float64 { return c.roll }
+
+			assert.Equal(t, c.want, i.Pick(c.side, c.method, c.clusterID))
+		})
+	}
+
+	i.SetActive(false)
+	assert.Nil(t, i.Pick(HTTPSide, "/api/v1/metrics", ""))
+}
+
+func TestInjectorSetRules(t *testing.T) {
+	i, err := New(nil)
+	require.NoError(t, err)
+
+	assert.Error(t, i.SetRules([]configs.FaultRule{{Percentage: 100, Code: "BROKEN"}}))
+	assert.Error(t, i.SetRules([]configs.FaultRule{{Percentage: 101}}))
+
+	rules := []configs.FaultRule{{Percentage: 10, Drop: true}}
+	assert.NoError(t, i.SetRules(rules))
+	assert.Equal(t, rules, i.Rules())
+}
+
+func TestFor(t *testing.T) {
+	grpcConf := &configs.FaultInjection{
+		Name:  "test-shared",
+		Rules: []configs.FaultRule{{Methods: []string{"services.GatewaySaverService"}, Percentage: 100, Code: "UNAVAILABLE"}},
+	}
+	httpConf := &configs.FaultInjection{
+		Name:   "test-shared",
+		Active: true,
+		Rules:  []configs.FaultRule{{Methods: []string{"/api/v1/"}, Percentage: 100, HTTPStatus: 503}},
+	}
+
+	i, err := For(grpcConf)
+	require.NoError(t, err)
+	assert.False(t, i.Active())
+
+	shared, err := For(httpConf)
+	require.NoError(t, err)
+	assert.Same(t, i, shared)
+
+	// the configs are joined once
+	shared, err = For(grpcConf)
+	require.NoError(t, err)
+	assert.Same(t, i, shared)
+
+	assert.True(t, i.Active())
+	assert.Equal(t, append(grpcConf.Rules, httpConf.Rules...), i.Rules())
+
+	other, err := For(&configs.FaultInjection{Name: "test-other"})
+	require.NoError(t, err)
+	assert.NotSame(t, i, other)
+}
@@ -0,0 +1,30 @@
+package fault
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	injectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "fault_injected_total",
+		Help: "Total number of injected faults by side, matched method pattern and fault (latency, error or drop).",
+	}, []string{"side", "method", "fault"})
+
+	activeGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "fault_injection_active",
+		Help: "Whether the fault injection is active (1) or not (0).",
+	})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(
+			injectedTotal,
+			activeGauge,
+		)
+	})
+}
@@ -0,0 +1,108 @@
+// Package brotli_compressor is a wrapper for using github.com/andybalholm/brotli
+// with gRPC.
+package brotli_compressor
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/andybalholm/brotli"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+const Name = "brotli"
+
+type compressor struct {
+	// current holds *settings, it is replaced by Configure
+	current atomic.Value
+}
+
+type settings struct {
+	level   int
+	maxSize uint64
+
+	writers sync.Pool
+	readers sync.Pool
+}
+
+func init() {
+	c := &compressor{}
+	c.current.Store(&settings{level: brotli.DefaultCompression, maxSize: grpcC.DefaultMaxDecompressedSize})
+
+	encoding.RegisterCompressor(c)
+}
+
+// Configure updates the registered compressor with the level and the decompressed size limit
+// of the compression config, the zero level is the default brotli level and 1-11 are the explicit ones.
+func Configure(conf *configs.Compression) error {
+	if conf.Level < 0 || conf.Level > brotli.BestCompression {
+		return fmt.Errorf("invalid brotli compression level: %d", conf.Level)
+	}
+
+	level := brotli.DefaultCompression
+	if conf.Level > 0 {
+		level = conf.Level
+	}
+
+	encoding.GetCompressor(Name).(*compressor).current.Store(&settings{level: level, maxSize: grpcC.MaxDecompressedSize(conf)})
+
+	return nil
+}
+
+func (c *compressor) load() *settings {
+	return c.current.Load().(*settings)
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	s := c.load()
+
+	bw, ok := s.writers.Get().(*brotli.Writer)
+	if !ok {
+		bw = brotli.NewWriterLevel(w, s.level)
+	} else {
+		bw.Reset(w)
+	}
+
+	return &brotliWriteCloser{Writer: bw, pool: &s.writers}, nil
+}
+
+type brotliWriteCloser struct {
+	*brotli.Writer
+	pool *sync.Pool
+}
+
+func (z *brotliWriteCloser) Close() error {
+	err := z.Writer.Close()
+
+	// release the destination writer before the brotli writer is pooled
+	z.Writer.Reset(nil)
+	z.pool.Put(z.Writer)
+
+	return err
+}
+
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	s := c.load()
+
+	br, ok := s.readers.Get().(*brotli.Reader)
+	if !ok {
+		br = brotli.NewReader(r)
+	} else if err := br.Reset(r); err != nil {
+		return nil, err
+	}
+
+	return grpcC.NewLimitedReader(br, s.maxSize, func() {
+		// release the source reader before the brotli reader is pooled
+		_ = br.Reset(nil)
+		s.readers.Put(br)
+	}), nil
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
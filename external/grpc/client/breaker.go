@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/breaker"
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+// CircuitBreakerClientInterceptor rejects the calls with the Unavailable code while the breaker
+// of the connection target is open, pass it with the internal interceptors of SetGrpcClientOptions.
+func CircuitBreakerClientInterceptor(conf *configs.CircuitBreaker) grpc.UnaryClientInterceptor {
+	group := breaker.NewGroup(conf)
+
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if !conf.Enabled {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		done, err := group.Get(cc.Target()).Allow()
+		if err != nil {
+			return status.Errorf(codes.Unavailable, "%s: %v", method, err)
+		}
+
+		start := time.Now()
+		success := false
+
+		// the panicked calls are counted as the failures
+		defer func() {
+			done(success, time.Since(start))
+		}()
+
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		success = !isBreakerFailure(err)
+
+		return err
+	}
+}
+
+// isBreakerFailure reports whether the call error is caused by the backend state,
+// the errors of the request itself don't trip the breaker.
+func isBreakerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
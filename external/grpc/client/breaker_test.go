@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+func TestCircuitBreakerClientInterceptorPanic(t *testing.T) {
+	cc, err := grpc.Dial("breaker-panic", grpc.WithInsecure())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = cc.Close()
+	})
+
+	interceptor := CircuitBreakerClientInterceptor(&configs.CircuitBreaker{
+		Enabled:     true,
+		MinRequests: 1,
+		OpenTimeout: time.Minute,
+	})
+
+	assert.Panics(t, func() {
+		_ = interceptor(context.Background(), sendMetricsMethod, nil, nil, cc,
+			func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+				panic("boom")
+			})
+	})
+
+	// the panicked call is counted as the failure and opens the breaker
+	err = interceptor(context.Background(), sendMetricsMethod, nil, nil, cc,
+		func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+			return nil
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/grpc/compressors"
+)
+
+// CompressionUnaryClientInterceptor compresses the calls with the method compression type
+// when the request is not smaller than the minimum payload size.
+func CompressionUnaryClientInterceptor(conf *configs.Compression) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		name := compressors.Name(conf.TypeFor(method))
+
+		if msg, ok := req.(proto.Message); ok && conf.MinSize > 0 && uint(proto.Size(msg)) < conf.MinSize {
+			name = ""
+		}
+
+		if len(name) > 0 {
+			// the caller options go last to override the configured compressor
+			opts = append([]grpc.CallOption{grpc.UseCompressor(name)}, opts...)
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// CompressionStreamClientInterceptor compresses the streams with the method compression type,
+// the minimum payload size isn't applied to the streams.
+func CompressionStreamClientInterceptor(conf *configs.Compression) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		if name := compressors.Name(conf.TypeFor(method)); len(name) > 0 {
+			opts = append([]grpc.CallOption{grpc.UseCompressor(name)}, opts...)
+		}
+
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+)
+
+func TestCompressionUnaryClientInterceptor(t *testing.T) {
+	conf := &configs.Compression{
+		Enabled: true,
+		Type:    enums.Zstd,
+		MinSize: 64,
+		Methods: map[string]enums.CompressionType{
+			"services.GatewaySaverService/GetMetricsOffset": enums.None,
+			"services.OtherService":                         enums.Gzip,
+		},
+	}
+
+	large := &pb.ReqSendMetrics{Header: &pb.Header{ClusterId: strings.Repeat("c", 128)}}
+	small := &pb.ReqSendMetrics{Header: &pb.Header{ClusterId: "c"}}
+
+	var cases = []struct {
+		name   string
+		conf   *configs.Compression
+		method string
+		req    interface{}
+		opts   []grpc.CallOption
+		want   string
+	}{
+		{
+			name:   "default type",
+			conf:   conf,
+			method: "/services.GatewaySaverService/SendMetrics",
+			req:    large,
+			want:   "zstd",
+		},
+		{
+			name:   "payload smaller than the minimum size",
+			conf:   conf,
+			method: "/services.GatewaySaverService/SendMetrics",
+			req:    small,
+		},
+		{
+			name:   "method override disables compression",
+			conf:   conf,
+			method: "/services.GatewaySaverService/GetMetricsOffset",
+			req:    large,
+		},
+		{
+			name:   "service override",
+			conf:   conf,
+			method: "/services.OtherService/Call",
+			req:    large,
+			want:   "gzip",
+		},
+		{
+			name:   "caller compressor wins",
+			conf:   conf,
+			method: "/services.GatewaySaverService/SendMetrics",
+			req:    large,
+			opts:   []grpc.CallOption{grpc.UseCompressor("snappy")},
+			want:   "snappy",
+		},
+		{
+			name:   "compression disabled",
+			conf:   &configs.Compression{Type: enums.Zstd},
+			method: "/services.GatewaySaverService/SendMetrics",
+			req:    large,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got string
+
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				for _, opt := range opts {
+					if compressor, ok := opt.(grpc.CompressorCallOption); ok {
+						got = compressor.CompressorType
+					}
+				}
+
+				return nil
+			}
+
+			err := CompressionUnaryClientInterceptor(c.conf)(context.Background(), c.method, c.req, nil, nil, invoker, c.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
@@ -0,0 +1,380 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+const (
+	DefaultTokenRefreshBefore = time.Minute
+	DefaultExchangeTimeout    = 10 * time.Second
+	DefaultExchangeBackoff    = time.Second
+	MaxExchangeBackoff        = time.Minute
+
+	exchangeKey = "exchange"
+
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	jwtTokenType           = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// Token is the access token of the client calls, the zero Expiry means that the token never expires.
+type Token struct {
+	Value  string
+	Expiry time.Time
+}
+
+// Valid reports whether the token is set and is not going to expire within the refresh window.
+func (t *Token) Valid(refreshBefore time.Duration) bool {
+	if t == nil || len(t.Value) == 0 {
+		return false
+	}
+
+	return t.Expiry.IsZero() || time.Until(t.Expiry) > refreshBefore
+}
+
+// TokenSource returns the current token of the client calls.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// NewTokenSource returns the token source of the client configs, the token is the static client token
+// or the content of the token file, it is exchanged on the credentials endpoint when the endpoint is set.
+func NewTokenSource(conf configs.Client) (TokenSource, error) {
+	creds := conf.Credentials
+	if creds == nil {
+		return StaticTokenSource(conf.Token), nil
+	}
+
+	var source TokenSource
+	if len(creds.TokenFile) > 0 {
+		source = FileTokenSource(creds.TokenFile)
+	} else {
+		source = StaticTokenSource(conf.Token)
+	}
+
+	if len(creds.Endpoint) > 0 {
+		return ExchangeTokenSource(creds.Endpoint, source, creds.RefreshBefore, nil)
+	}
+
+	return source, nil
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+// StaticTokenSource returns the token source of the static token, the expiry is taken from the token claims.
+func StaticTokenSource(token string) TokenSource {
+	return &staticTokenSource{token: &Token{Value: token, Expiry: jwtExpiry(token)}}
+}
+
+func (s *staticTokenSource) Token(_ context.Context) (*Token, error) {
+	if len(s.token.Value) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "client token is not set")
+	}
+
+	return s.token, nil
+}
+
+type fileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	token   *Token
+	modTime time.Time
+	size    int64
+}
+
+// FileTokenSource returns the token source of the token file, the file is read again once it changes,
+// so the rotated tokens (e.g. the Kubernetes projected service account tokens) are picked up.
+func FileTokenSource(path string) TokenSource {
+	return &fileTokenSource{path: path}
+}
+
+func (s *fileTokenSource) Token(_ context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if s.token != nil {
+			return s.token, nil
+		}
+
+		return nil, fmt.Errorf("token file: %w", err)
+	}
+
+	if s.token != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.token, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("token file: %w", err)
+	}
+
+	value := strings.TrimSpace(string(data))
+	if len(value) == 0 {
+		return nil, fmt.Errorf("token file %s is empty", s.path)
+	}
+
+	s.token = &Token{Value: value, Expiry: jwtExpiry(value)}
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+
+	return s.token, nil
+}
+
+type exchangeTokenSource struct {
+	endpoint      string
+	subject       TokenSource
+	refreshBefore time.Duration
+	client        *http.Client
+
+	group singleflight.Group
+
+	mu         sync.Mutex
+	token      *Token
+	refreshing bool
+	failures   int
+	retryAt    time.Time
+	err        error
+}
+
+// ExchangeTokenSource returns the token source exchanging the subject token for the access token
+// on the endpoint by the OAuth 2.0 token exchange (RFC 8693). The access token is refreshed in the background
+// the refreshBefore duration before it expires and the current one is returned meanwhile, the concurrent calls
+// without the valid token share the single exchange. The failed exchanges are retried with the exponential backoff.
+func ExchangeTokenSource(endpoint string, subject TokenSource, refreshBefore time.Duration, client *http.Client) (TokenSource, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("token exchange endpoint: %w", err)
+	}
+
+	if refreshBefore <= 0 {
+		refreshBefore = DefaultTokenRefreshBefore
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: DefaultExchangeTimeout}
+	}
+
+	return &exchangeTokenSource{
+		endpoint:      endpoint,
+		subject:       subject,
+		refreshBefore: refreshBefore,
+		client:        client,
+	}, nil
+}
+
+func (s *exchangeTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+
+	token := s.token
+	switch {
+	case token.Valid(s.refreshBefore):
+		s.mu.Unlock()
+		return token, nil
+	case token.Valid(0):
+		if !s.refreshing && !time.Now().Before(s.retryAt) {
+			s.refreshing = true
+
+			go func() {
+				_, _, _ = s.group.Do(exchangeKey, s.refresh)
+			}()
+		}
+
+		s.mu.Unlock()
+
+		return token, nil
+	case time.Now().Before(s.retryAt):
+		err := s.err
+		s.mu.Unlock()
+
+		return nil, err
+	}
+
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case res := <-s.group.DoChan(exchangeKey, s.refresh):
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return res.Val.(*Token), nil
+	}
+}
+
+// refresh exchanges the token detached from the calls, so the cancelled call doesn't fail the others.
+func (s *exchangeTokenSource) refresh() (interface{}, error) {
+	token, err := s.exchange(context.Background())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshing = false
+
+	if err != nil {
+		s.failures++
+		s.retryAt = time.Now().Add(exchangeBackoff(s.failures))
+		s.err = err
+
+		return nil, err
+	}
+
+	s.token = token
+	s.failures = 0
+	s.retryAt = time.Time{}
+	s.err = nil
+
+	return token, nil
+}
+
+func exchangeBackoff(failures int) time.Duration {
+	if failures > 16 {
+		return MaxExchangeBackoff
+	}
+
+	backoff := DefaultExchangeBackoff << (failures - 1)
+	if backoff > MaxExchangeBackoff {
+		return MaxExchangeBackoff
+	}
+
+	return backoff
+}
+
+func (s *exchangeTokenSource) exchange(ctx context.Context) (*Token, error) {
+	subject, err := s.subject.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {subject.Value},
+		"subject_token_type": {jwtTokenType},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token exchange: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+
+	if len(body.AccessToken) == 0 {
+		return nil, fmt.Errorf("token exchange: empty access token")
+	}
+
+	token := &Token{Value: body.AccessToken, Expiry: jwtExpiry(body.AccessToken)}
+	if body.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+// jwtExpiry returns the expiry of the exp claim of the JWT token, the signature is not verified
+// and the zero time is returned for the tokens of the other formats.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
+
+// TokenCredentials are the per-RPC credentials sending the token of the token source by the token metadata key,
+// the token is requested for every unary call and stream, so the refreshed tokens are used by the next calls.
+type TokenCredentials struct {
+	source   TokenSource
+	insecure bool
+}
+
+// NewTokenCredentials returns the per-RPC credentials of the token source, the insecure credentials
+// are allowed to be sent over the connections without the transport security.
+func NewTokenCredentials(source TokenSource, insecure bool) *TokenCredentials {
+	return &TokenCredentials{source: source, insecure: insecure}
+}
+
+func (c *TokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := c.source.Token(ctx)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+
+		return nil, status.Errorf(codes.Unauthenticated, "client token: %v", err)
+	}
+
+	return map[string]string{grpcC.TokenKey: token.Value}, nil
+}
+
+func (c *TokenCredentials) RequireTransportSecurity() bool {
+	return !c.insecure
+}
+
+// WithTokenCredentials returns the dial option of the per-RPC credentials of the client configs.
+func WithTokenCredentials(conf configs.Client) (grpc.DialOption, error) {
+	source, err := NewTokenSource(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	insecure := conf.Credentials != nil && conf.Credentials.Insecure
+
+	return grpc.WithPerRPCCredentials(NewTokenCredentials(source, insecure)), nil
+}
@@ -0,0 +1,87 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	pb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/grpc/grpctest"
+)
+
+func TestClientToken(t *testing.T) {
+	exchange := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		fmt.Fprintf(w, `{"access_token":"access-%s","expires_in":3600}`, r.PostForm.Get("subject_token"))
+	}))
+	defer exchange.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token"), 0600))
+
+	var cases = []struct {
+		name string
+		conf *configs.Client
+		want []string
+	}{
+		{
+			name: "static token",
+			conf: &configs.Client{Token: "static-token"},
+			want: []string{"static-token"},
+		},
+		{
+			name: "token file credentials",
+			conf: &configs.Client{
+				Token:       "static-token",
+				Credentials: &configs.Credentials{TokenFile: tokenFile, Insecure: true},
+			},
+			want: []string{"file-token"},
+		},
+		{
+			name: "exchanged token credentials",
+			conf: &configs.Client{
+				Token:       "subject",
+				Credentials: &configs.Credentials{Endpoint: exchange.URL, Insecure: true},
+			},
+			want: []string{"access-subject"},
+		},
+		{
+			name: "anonymous client",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got []string
+
+			s := grpctest.New(t, func(s *grpc.Server) {
+				pb.RegisterHealthServer(s, health.NewServer())
+			},
+				grpctest.WithClient(c.conf),
+				grpctest.WithServerInterceptors(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+					md, _ := metadata.FromIncomingContext(ctx)
+					got = md.Get(grpcC.TokenKey)
+
+					return handler(ctx, req)
+				}),
+			)
+
+			_, err := pb.NewHealthClient(s.Conn).Check(context.Background(), &pb.HealthCheckRequest{})
+			require.NoError(t, err)
+
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
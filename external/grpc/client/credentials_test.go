@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+func testJWT(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJub25lIn0." + payload + ".c2ln"
+}
+
+func TestStaticTokenSource(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	token, err := StaticTokenSource(testJWT(exp)).Token(context.Background())
+	require.NoError(t, err)
+	assert.True(t, token.Expiry.Equal(exp))
+
+	_, err = StaticTokenSource("").Token(context.Background())
+	assert.Error(t, err)
+}
+
+func TestFileTokenSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0600))
+
+	source := FileTokenSource(path)
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "first", token.Value)
+
+	// the rotated token is read once the file changes
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "second", token.Value)
+}
+
+func TestExchangeTokenSource(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "subject", r.PostForm.Get("subject_token"))
+
+		n := atomic.AddInt32(&calls, 1)
+		fmt.Fprintf(w, `{"access_token":"access-%d","expires_in":%d}`, n, 120)
+	}))
+	defer srv.Close()
+
+	source, err := ExchangeTokenSource(srv.URL, StaticTokenSource("subject"), time.Minute, nil)
+	require.NoError(t, err)
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.Value)
+
+	// the token is cached until the refresh window
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.Value)
+
+	// the token is refreshed in the background before it expires, the current one is returned meanwhile
+	expireIn(source, 30*time.Second)
+
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.Value)
+
+	assert.Eventually(t, func() bool {
+		token, err = source.Token(context.Background())
+		return err == nil && token.Value == "access-2"
+	}, time.Second, 10*time.Millisecond)
+
+	// the current token is kept while the refresh fails
+	srv.Close()
+	expireIn(source, 30*time.Second)
+
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", token.Value)
+}
+
+func TestExchangeTokenSourceBackoff(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	source, err := ExchangeTokenSource(srv.URL, StaticTokenSource("subject"), time.Minute, nil)
+	require.NoError(t, err)
+
+	_, err = source.Token(context.Background())
+	require.Error(t, err)
+
+	// the calls during the backoff get the last error without the exchange
+	for i := 0; i < 5; i++ {
+		_, errBackoff := source.Token(context.Background())
+		assert.Equal(t, err, errBackoff)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	var cases = []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: DefaultExchangeBackoff},
+		{failures: 3, want: 4 * DefaultExchangeBackoff},
+		{failures: 10, want: MaxExchangeBackoff},
+		{failures: 100, want: MaxExchangeBackoff},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, exchangeBackoff(c.failures))
+	}
+}
+
+func expireIn(source TokenSource, d time.Duration) {
+	s := source.(*exchangeTokenSource)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := *s.token
+	token.Expiry = time.Now().Add(d)
+	s.token = &token
+}
+
+func TestTokenCredentials(t *testing.T) {
+	creds := NewTokenCredentials(StaticTokenSource("token"), true)
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{grpcC.TokenKey: "token"}, md)
+	assert.False(t, creds.RequireTransportSecurity())
+}
+
+func TestClientOptions(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token"), 0600))
+
+	var cases = []struct {
+		name     string
+		insecure bool
+		conf     configs.Client
+		wantErr  error
+	}{
+		{
+			name: "static token",
+			conf: configs.Client{Token: "static-token"},
+		},
+		{
+			name: "credentials of the secure connection",
+			conf: configs.Client{Credentials: &configs.Credentials{TokenFile: tokenFile}},
+		},
+		{
+			name:     "insecure credentials of the insecure connection",
+			insecure: true,
+			conf:     configs.Client{Credentials: &configs.Credentials{TokenFile: tokenFile, Insecure: true}},
+		},
+		{
+			name:     "secure credentials of the insecure connection",
+			insecure: true,
+			conf:     configs.Client{Credentials: &configs.Credentials{TokenFile: tokenFile}},
+			wantErr:  ErrInsecureCredentials,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ClientOptions(&configs.GRPC{Conn: &configs.Connection{Insecure: c.insecure}}, c.conf)
+			assert.ErrorIs(t, err, c.wantErr)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+// DeadlineClientInterceptor applies the configured method timeout to unary calls without deadline.
+func DeadlineClientInterceptor(conf *configs.Deadlines) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) (err error) {
+		if _, ok := ctx.Deadline(); !ok {
+			if timeout := conf.TimeoutFor(method); timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
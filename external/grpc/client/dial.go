@@ -0,0 +1,124 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/health" // registers the client health checking function
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+)
+
+const (
+	staticScheme = "static"
+)
+
+// Dial creates the client connection with SetGrpcClientOptions, the load balancing config selects
+// the name resolver of the backend replicas and the service config of the balancing policy.
+func Dial(conf *configs.GRPC, baseConf *configs.Base, internalInterceptors ...grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+	return DialWithLogger(conf, baseConf, nil, internalInterceptors...)
+}
+
+// DialWithLogger is Dial with the logger of the calls logging.
+func DialWithLogger(conf *configs.GRPC, baseConf *configs.Base, logger *zap.SugaredLogger, internalInterceptors ...grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+	options, err := SetGrpcClientOptionsWithLogger(conf, baseConf, logger, internalInterceptors...)
+	if err != nil {
+		return nil, err
+	}
+
+	return dial(conf, options)
+}
+
+// DialWithCredentials is DialWithLogger with the options of the client configs returned by ClientOptions.
+func DialWithCredentials(conf *configs.GRPC, baseConf *configs.Base, clientConf configs.Client, logger *zap.SugaredLogger, internalInterceptors ...grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+	options, err := SetGrpcClientOptionsWithLogger(conf, baseConf, logger, internalInterceptors...)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOptions, err := ClientOptions(conf, clientConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return dial(conf, append(options, clientOptions...))
+}
+
+func dial(conf *configs.GRPC, options []grpc.DialOption) (*grpc.ClientConn, error) {
+	target, lbOptions, err := LoadBalancingOptions(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.Dial(target, append(options, lbOptions...)...)
+}
+
+// LoadBalancingOptions returns the dial target and the options of the client load balancing config.
+func LoadBalancingOptions(conf *configs.GRPC) (target string, options []grpc.DialOption, err error) {
+	address := net.JoinHostPort(conf.Conn.Host, fmt.Sprint(conf.Conn.Port))
+
+	lb := conf.LoadBalancing
+	if lb == nil {
+		return address, nil, nil
+	}
+
+	switch lb.Resolver {
+	case enums.DNS:
+		target = "dns:///" + address
+	case enums.Static:
+		endpoints := lb.Endpoints
+		if len(endpoints) == 0 {
+			endpoints = []string{address}
+		}
+
+		state := resolver.State{}
+		for _, endpoint := range endpoints {
+			state.Addresses = append(state.Addresses, resolver.Address{Addr: endpoint})
+		}
+
+		r := manual.NewBuilderWithScheme(staticScheme)
+		r.InitialState(state)
+
+		target = staticScheme + ":///" + conf.Conn.Host
+		options = append(options, grpc.WithResolvers(r))
+	default:
+		return "", nil, fmt.Errorf("unsupported grpc resolver: %s", lb.Resolver)
+	}
+
+	serviceConfig, err := ServiceConfig(lb)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return target, append(options, grpc.WithDefaultServiceConfig(serviceConfig)), nil
+}
+
+// ServiceConfig returns the JSON service config of the balancing policy with the optional health checking.
+func ServiceConfig(lb *configs.LoadBalancing) (string, error) {
+	type healthCheckConfig struct {
+		ServiceName string `json:"serviceName"`
+	}
+
+	type serviceConfig struct {
+		LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+		HealthCheckConfig   *healthCheckConfig    `json:"healthCheckConfig,omitempty"`
+	}
+
+	out := serviceConfig{
+		LoadBalancingConfig: []map[string]struct{}{{lb.Policy.String(): {}}},
+	}
+
+	if lb.HealthCheck {
+		out.HealthCheckConfig = &healthCheckConfig{ServiceName: lb.HealthServiceName}
+	}
+
+	data, err := json.Marshal(out)
+
+	return string(data), err
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	pb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+)
+
+type testBackend struct {
+	addr   string
+	health *health.Server
+	calls  int64
+}
+
+func startTestBackend(t *testing.T) *testBackend {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	b := &testBackend{addr: lis.Addr().String(), health: health.NewServer()}
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		atomic.AddInt64(&b.calls, 1)
+		return handler(ctx, req)
+	}))
+	pb.RegisterHealthServer(srv, b.health)
+
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+
+	t.Cleanup(srv.Stop)
+
+	return b
+}
+
+func TestDialLoadBalancing(t *testing.T) {
+	var cases = []struct {
+		name        string
+		policy      enums.BalancingPolicy
+		healthCheck bool
+		notServing  int
+		wantBoth    bool
+	}{
+		{
+			name:     "round robin",
+			policy:   enums.RoundRobin,
+			wantBoth: true,
+		},
+		{
+			name:   "pick first",
+			policy: enums.PickFirst,
+		},
+		{
+			name:        "round robin skips not serving backends",
+			policy:      enums.RoundRobin,
+			healthCheck: true,
+			notServing:  1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backends := []*testBackend{startTestBackend(t), startTestBackend(t)}
+			if c.healthCheck {
+				backends[c.notServing].health.SetServingStatus("", pb.HealthCheckResponse_NOT_SERVING)
+			}
+
+			conf := &configs.GRPC{
+				Conn: &configs.Connection{Host: "backends", Port: 1, Insecure: true},
+				LoadBalancing: &configs.LoadBalancing{
+					Resolver:    enums.Static,
+					Policy:      c.policy,
+					Endpoints:   []string{backends[0].addr, backends[1].addr},
+					HealthCheck: c.healthCheck,
+				},
+			}
+
+			conn, err := Dial(conf, &configs.Base{})
+			require.NoError(t, err)
+			defer conn.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			client := pb.NewHealthClient(conn)
+			for i := 0; i < 20; i++ {
+				_, err = client.Check(ctx, &pb.HealthCheckRequest{}, grpc.WaitForReady(true))
+				require.NoError(t, err)
+			}
+
+			used := 0
+			for _, b := range backends {
+				if atomic.LoadInt64(&b.calls) > 0 {
+					used++
+				}
+			}
+
+			if c.wantBoth {
+				assert.Equal(t, 2, used)
+			} else {
+				assert.Equal(t, 1, used)
+			}
+
+			if c.healthCheck {
+				assert.Zero(t, atomic.LoadInt64(&backends[c.notServing].calls))
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/fault"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+// FaultClientInterceptor injects the faults of the injector into the unary calls, the dropped calls are sent
+// but their responses are discarded and the calls wait for the context to be done.
+func FaultClientInterceptor(i *fault.Injector) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		f := i.Pick(fault.ClientSide, method, outgoingClusterID(ctx))
+		if f == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		if err := f.Delay(ctx); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		if err := f.Err(); err != nil {
+			return err
+		}
+
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil || !f.Drop {
+			return err
+		}
+
+		<-ctx.Done()
+
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// FaultStreamClientInterceptor injects the faults of the injector into the streams,
+// the messages of the dropped streams are never received.
+func FaultStreamClientInterceptor(i *fault.Injector) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		f := i.Pick(fault.ClientSide, method, outgoingClusterID(ctx))
+		if f == nil {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		if err := f.Delay(ctx); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+
+		if err := f.Err(); err != nil {
+			return nil, err
+		}
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil || !f.Drop {
+			return stream, err
+		}
+
+		return &droppedClientStream{ClientStream: stream}, nil
+	}
+}
+
+type droppedClientStream struct {
+	grpc.ClientStream
+}
+
+func (s *droppedClientStream) RecvMsg(_ interface{}) error {
+	<-s.Context().Done()
+
+	return status.FromContextError(s.Context().Err()).Err()
+}
+
+func outgoingClusterID(ctx context.Context) string {
+	md, _ := metadata.FromOutgoingContext(ctx)
+
+	return grpcC.MetadataValue(md, grpcC.ClusterIDKey)
+}
@@ -2,28 +2,41 @@ package client
 
 import (
 	"context"
-
-	_ "github.com/dysnix/predictkube-libs/external/grpc/zstd_compressor"
-	_ "google.golang.org/grpc/encoding/gzip"
+	"crypto/tls"
+	"errors"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/status"
 
 	"github.com/dysnix/predictkube-libs/external/configs"
-	"github.com/dysnix/predictkube-libs/external/enums"
-	"github.com/dysnix/predictkube-libs/external/grpc/zstd_compressor"
+	"github.com/dysnix/predictkube-libs/external/fault"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/grpc/compressors"
 )
 
 const (
 	DefaultMaxMsgSize = 2 << 20 // 2Mb
 )
 
+// ErrInsecureCredentials is returned for the secure per-RPC credentials of the insecure connection,
+// they have to be marked as insecure explicitly to be sent in plaintext.
+var ErrInsecureCredentials = errors.New("grpc: the insecure connection requires the insecure credentials")
+
+// SetGrpcClientOptions returns the dial options of the configs with the interceptors chain of the library,
+// the calls logging is discarded, use SetGrpcClientOptionsWithLogger to keep it. The options of the client
+// metadata and the token credentials are returned by ClientOptions.
 func SetGrpcClientOptions(conf *configs.GRPC, baseConf *configs.Base, internalInterceptors ...grpc.UnaryClientInterceptor) (options []grpc.DialOption, err error) {
+	return SetGrpcClientOptionsWithLogger(conf, baseConf, nil, internalInterceptors...)
+}
+
+// SetGrpcClientOptionsWithLogger is SetGrpcClientOptions with the logger of the calls logging.
+func SetGrpcClientOptionsWithLogger(conf *configs.GRPC, baseConf *configs.Base, logger *zap.SugaredLogger, internalInterceptors ...grpc.UnaryClientInterceptor) (options []grpc.DialOption, err error) {
 	unaryClientInterceptors := make([]grpc.UnaryClientInterceptor, 0)
 	streamClientInterceptors := make([]grpc.StreamClientInterceptor, 0)
 
@@ -38,11 +51,8 @@ func SetGrpcClientOptions(conf *configs.GRPC, baseConf *configs.Base, internalIn
 			))
 	}
 
-	switch conf.Compression.Type {
-	case enums.Gzip:
-		options = append(options, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
-	case enums.Zstd:
-		options = append(options, grpc.WithDefaultCallOptions(grpc.UseCompressor(zstd_compressor.Name)))
+	if err = compressors.Configure(&conf.Compression); err != nil {
+		return nil, err
 	}
 
 	if conf.Conn.Timeout > 0 {
@@ -69,8 +79,19 @@ func SetGrpcClientOptions(conf *configs.GRPC, baseConf *configs.Base, internalIn
 		options = append(options, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(DefaultMaxMsgSize)))
 	}
 
-	if conf.Conn.Insecure {
+	switch {
+	case conf.Conn.Insecure:
 		options = append(options, grpc.WithInsecure())
+	case conf.TLS != nil:
+		tlsConf, err := grpcC.NewClientTLSConfig(conf.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, grpc.WithTransportCredentials(credentials.NewTLS(tlsConf)))
+	default:
+		// verify the server with the system roots
+		options = append(options, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})))
 	}
 
 	// TODO: implement all needed interceptors...
@@ -78,21 +99,88 @@ func SetGrpcClientOptions(conf *configs.GRPC, baseConf *configs.Base, internalIn
 	unaryClientInterceptors = append(unaryClientInterceptors,
 		PanicClientInterceptor(func(ctx context.Context, err error, params ...interface{}) error {
 			//TODO:? can be any other logic...
-			return status.Errorf(codes.Unknown, "panic triggered: %v", err)
+			return status.Errorf(codes.Internal, "panic triggered: %v", err)
 		}))
 
+	if baseConf.Tracing.Enabled {
+		unaryClientInterceptors = append(unaryClientInterceptors, TracingUnaryClientInterceptor())
+		streamClientInterceptors = append(streamClientInterceptors, TracingStreamClientInterceptor())
+	}
+
 	if baseConf.Monitoring.Enabled {
+		registerMetrics()
+
 		unaryClientInterceptors = append(unaryClientInterceptors, grpc_prometheus.UnaryClientInterceptor)
 		streamClientInterceptors = append(streamClientInterceptors, grpc_prometheus.StreamClientInterceptor)
 	}
 
+	if conf.Logging != nil && conf.Logging.Enabled {
+		if logger == nil {
+			logger = zap.NewNop().Sugar()
+		}
+
+		unaryClientInterceptors = append(unaryClientInterceptors, LoggingUnaryClientInterceptor(logger, conf.Logging))
+		streamClientInterceptors = append(streamClientInterceptors, LoggingStreamClientInterceptor(logger, conf.Logging))
+	}
+
+	if conf.Deadlines != nil {
+		unaryClientInterceptors = append(unaryClientInterceptors, DeadlineClientInterceptor(conf.Deadlines))
+	}
+
+	if conf.Compression.Enabled {
+		unaryClientInterceptors = append(unaryClientInterceptors, CompressionUnaryClientInterceptor(&conf.Compression))
+		streamClientInterceptors = append(streamClientInterceptors, CompressionStreamClientInterceptor(&conf.Compression))
+	}
+
+	if conf.Idempotency != nil && conf.Idempotency.Enabled {
+		unaryClientInterceptors = append(unaryClientInterceptors, IdempotencyClientInterceptor(conf.Idempotency))
+	}
+
+	if conf.Retry != nil && conf.Retry.Enabled {
+		unaryClientInterceptors = append(unaryClientInterceptors, RetryClientInterceptor(conf.Retry))
+	}
+
 	unaryClientInterceptors = append(unaryClientInterceptors, internalInterceptors...)
 
+	// the faults are injected closest to the wire, so the retries and the metrics see them as the real ones
+	if conf.FaultInjection != nil && conf.FaultInjection.Enabled {
+		injector, err := fault.For(conf.FaultInjection)
+		if err != nil {
+			return nil, err
+		}
+
+		unaryClientInterceptors = append(unaryClientInterceptors, FaultClientInterceptor(injector))
+		streamClientInterceptors = append(streamClientInterceptors, FaultStreamClientInterceptor(injector))
+	}
+
 	options = append(options,
 		grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(
 			unaryClientInterceptors...,
 		)),
+		grpc.WithStreamInterceptor(grpc_middleware.ChainStreamClient(
+			streamClientInterceptors...,
+		)),
 	)
 
 	return options, err
 }
+
+// ClientOptions returns the dial options of the client configs, they inject the client metadata into the calls
+// after the interceptors chain and install the per-RPC token credentials. The credentials are sent over
+// the insecure connection only when they are insecure too.
+func ClientOptions(conf *configs.GRPC, clientConf configs.Client) (options []grpc.DialOption, err error) {
+	if clientConf.Credentials != nil {
+		if conf.Conn.Insecure && !clientConf.Credentials.Insecure {
+			return nil, ErrInsecureCredentials
+		}
+
+		tokenOption, err := WithTokenCredentials(clientConf)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, tokenOption)
+	}
+
+	return append(options, grpc.WithChainUnaryInterceptor(InjectClientMetadataInterceptor(clientConf))), nil
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+// WithIdempotencyKey sets the idempotency key of the call, the duplicates of the call
+// with the same key get the result of the first one from the server.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, grpcC.IdempotencyKey, key)
+}
+
+// IdempotencyClientInterceptor generates the idempotency key for the calls of the configured methods
+// without one, it goes before the retry interceptor so all the attempts share the key.
+func IdempotencyClientInterceptor(conf *configs.Idempotency) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if conf.Matches(method) {
+			if md, ok := metadata.FromOutgoingContext(ctx); !ok || len(md.Get(grpcC.IdempotencyKey)) == 0 {
+				ctx = WithIdempotencyKey(ctx, uuid.NewString())
+			}
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+func TestIdempotencyClientInterceptor(t *testing.T) {
+	conf := &configs.Idempotency{Enabled: true, Methods: []string{"services.GatewaySaver"}}
+
+	var cases = []struct {
+		name    string
+		ctx     context.Context
+		method  string
+		wantKey string
+		wantNew bool
+	}{
+		{
+			name:    "key is generated for configured service",
+			ctx:     context.Background(),
+			method:  "/services.GatewaySaver/SendMetrics",
+			wantNew: true,
+		},
+		{
+			name:    "caller key is kept",
+			ctx:     WithIdempotencyKey(context.Background(), "batch-1"),
+			method:  "/services.GatewaySaver/SendMetrics",
+			wantKey: "batch-1",
+		},
+		{
+			name:   "not configured method",
+			ctx:    context.Background(),
+			method: "/services.Auth/CreateClient",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var keys []string
+
+			err := IdempotencyClientInterceptor(conf)(c.ctx, c.method, nil, nil, nil,
+				func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+					md, _ := metadata.FromOutgoingContext(ctx)
+					keys = md.Get(grpcC.IdempotencyKey)
+					return nil
+				})
+			assert.NoError(t, err)
+
+			switch {
+			case c.wantNew:
+				assert.Len(t, keys, 1)
+				assert.NotEmpty(t, keys[0])
+			case len(c.wantKey) > 0:
+				assert.Equal(t, []string{c.wantKey}, keys)
+			default:
+				assert.Empty(t, keys)
+			}
+		})
+	}
+}
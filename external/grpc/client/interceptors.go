@@ -28,7 +28,12 @@ func InjectClientMetadataInterceptor(conf configs.Client) grpc.UnaryClientInterc
 			ctx = metadata.AppendToOutgoingContext(ctx, grpcC.ClusterIDKey, conf.ClusterID)
 		}
 
-		return invoker(metadata.AppendToOutgoingContext(ctx, grpcC.TokenKey, conf.Token), method, req, reply, cc, opts...)
+		// the refreshed tokens are sent by the per-RPC credentials, see WithTokenCredentials
+		if len(conf.Token) > 0 && conf.Credentials == nil {
+			ctx = metadata.AppendToOutgoingContext(ctx, grpcC.TokenKey, conf.Token)
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
 	}
 }
 
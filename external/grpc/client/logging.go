@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+const (
+	unaryCallMsg  = "finished client unary call"
+	streamCallMsg = "finished client streaming call"
+)
+
+func LoggingUnaryClientInterceptor(logger *zap.SugaredLogger, conf *configs.Logging) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) (err error) {
+		var p peer.Peer
+		start := time.Now()
+
+		err = invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(&p))...)
+
+		code := status.Code(err)
+		if grpcC.ShouldLog(conf, method, code) {
+			fields := append(callFields(ctx, method, start, err),
+				"grpc.request_size", grpcC.MessageSize(req),
+			)
+
+			if err == nil {
+				fields = append(fields, "grpc.response_size", grpcC.MessageSize(reply))
+			}
+
+			if p.Addr != nil {
+				fields = append(fields, "peer.address", p.Addr.String())
+			}
+
+			grpcC.LogCall(logger, code, unaryCallMsg, fields...)
+		}
+
+		return err
+	}
+}
+
+func LoggingStreamClientInterceptor(logger *zap.SugaredLogger, conf *configs.Logging) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		start := time.Now()
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			if code := status.Code(err); grpcC.ShouldLog(conf, method, code) {
+				grpcC.LogCall(logger, code, streamCallMsg, callFields(ctx, method, start, err)...)
+			}
+
+			return nil, err
+		}
+
+		stream := &loggingClientStream{
+			ClientStream:  cs,
+			serverStreams: desc.ServerStreams,
+			done:          make(chan struct{}),
+			finish: func(s *loggingClientStream, err error) {
+				if code := status.Code(err); grpcC.ShouldLog(conf, method, code) {
+					grpcC.LogCall(logger, code, streamCallMsg,
+						append(callFields(ctx, method, start, err), s.stats.Fields()...)...)
+				}
+			},
+		}
+
+		// the abandoned streams are logged when the caller cancels them, grpc itself keeps
+		// the streams which are neither read to the end nor cancelled (grpc-go#1818)
+		go func() {
+			select {
+			case <-ctx.Done():
+				stream.finishOnce(status.FromContextError(ctx.Err()).Err())
+			case <-stream.done:
+			}
+		}()
+
+		return stream, nil
+	}
+}
+
+func callFields(ctx context.Context, method string, start time.Time, err error) []interface{} {
+	fields := []interface{}{
+		"grpc.method", method,
+		"grpc.code", status.Code(err).String(),
+		"grpc.duration", time.Since(start),
+	}
+
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		fields = append(fields,
+			"cluster_id", grpcC.MetadataValue(md, grpcC.ClusterIDKey),
+			"client_name", grpcC.MetadataValue(md, grpcC.NameKey),
+		)
+	}
+
+	if err != nil {
+		fields = append(fields, "error", err.Error())
+	}
+
+	return fields
+}
+
+type loggingClientStream struct {
+	grpc.ClientStream
+
+	serverStreams bool
+	stats         grpcC.StreamStats
+
+	once   sync.Once
+	done   chan struct{}
+	finish func(s *loggingClientStream, err error)
+}
+
+func (s *loggingClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.stats.Sent(m)
+	}
+
+	return err
+}
+
+func (s *loggingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.stats.Received(m)
+
+		// the client streaming calls end with the single response, e.g. CloseAndRecv
+		if !s.serverStreams {
+			s.finishOnce(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.finishOnce(nil)
+	default:
+		s.finishOnce(err)
+	}
+
+	return err
+}
+
+func (s *loggingClientStream) finishOnce(err error) {
+	s.once.Do(func() {
+		close(s.done)
+		s.finish(s, err)
+	})
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+)
+
+const sendMetricsMethod = "/services.GatewaySaverService/SendMetrics"
+
+type testClientStream struct {
+	grpc.ClientStream
+
+	mu       sync.Mutex
+	messages int
+	err      error
+}
+
+func (s *testClientStream) SendMsg(interface{}) error {
+	return nil
+}
+
+func (s *testClientStream) RecvMsg(interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.messages == 0 {
+		return s.err
+	}
+
+	s.messages--
+
+	return nil
+}
+
+func observedLogger() (*zap.SugaredLogger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(core).Sugar(), logs
+}
+
+func loggedStream(t *testing.T, ctx context.Context, desc *grpc.StreamDesc, cs grpc.ClientStream) (grpc.ClientStream, *observer.ObservedLogs) {
+	logger, logs := observedLogger()
+
+	stream, err := LoggingStreamClientInterceptor(logger, &configs.Logging{Enabled: true, SampleRate: 1})(ctx, desc, nil, sendMetricsMethod,
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return cs, nil
+		})
+	require.NoError(t, err)
+
+	return stream, logs
+}
+
+func TestLoggingUnaryClientInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     *configs.Logging
+		err      error
+		expected int
+		level    zapcore.Level
+	}{
+		{"disabled", &configs.Logging{}, nil, 0, zapcore.InfoLevel},
+		{"sampled out", &configs.Logging{Enabled: true}, nil, 0, zapcore.InfoLevel},
+		{"suppressed", &configs.Logging{Enabled: true, SampleRate: 1, SuppressMethods: []string{"services.GatewaySaverService"}}, nil, 0, zapcore.InfoLevel},
+		{"success", &configs.Logging{Enabled: true, SampleRate: 1}, nil, 1, zapcore.InfoLevel},
+		{"failure is always logged", &configs.Logging{Enabled: true}, status.Error(codes.Internal, "boom"), 1, zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger()
+
+			err := LoggingUnaryClientInterceptor(logger, tt.conf)(context.Background(), sendMetricsMethod,
+				&pb.ReqSendMetrics{}, &pb.ResSendMetrics{}, nil,
+				func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+					return tt.err
+				})
+			assert.Equal(t, tt.err, err)
+
+			require.Equal(t, tt.expected, logs.Len())
+			if tt.expected > 0 {
+				entry := logs.All()[0]
+				assert.Equal(t, tt.level, entry.Level)
+				assert.Equal(t, sendMetricsMethod, entry.ContextMap()["grpc.method"])
+			}
+		})
+	}
+}
+
+func TestLoggingStreamClientInterceptor(t *testing.T) {
+	t.Run("client streaming ends with the response", func(t *testing.T) {
+		stream, logs := loggedStream(t, context.Background(), &grpc.StreamDesc{ClientStreams: true}, &testClientStream{messages: 1})
+
+		require.NoError(t, stream.SendMsg(&pb.ReqSendMetrics{}))
+		require.NoError(t, stream.SendMsg(&pb.ReqSendMetrics{}))
+		require.NoError(t, stream.RecvMsg(&pb.ResSendMetrics{}))
+
+		require.Equal(t, 1, logs.Len())
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, codes.OK.String(), fields["grpc.code"])
+		assert.EqualValues(t, 2, fields["grpc.sent_messages"])
+		assert.EqualValues(t, 1, fields["grpc.received_messages"])
+	})
+
+	t.Run("server streaming ends with EOF", func(t *testing.T) {
+		stream, logs := loggedStream(t, context.Background(), &grpc.StreamDesc{ServerStreams: true}, &testClientStream{messages: 2, err: io.EOF})
+
+		require.NoError(t, stream.RecvMsg(&pb.ResSendMetrics{}))
+		require.NoError(t, stream.RecvMsg(&pb.ResSendMetrics{}))
+		assert.Equal(t, 0, logs.Len())
+
+		assert.Equal(t, io.EOF, stream.RecvMsg(&pb.ResSendMetrics{}))
+		require.Equal(t, 1, logs.Len())
+		assert.EqualValues(t, 2, logs.All()[0].ContextMap()["grpc.received_messages"])
+	})
+
+	t.Run("abandoned stream is logged on cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, logs := loggedStream(t, ctx, &grpc.StreamDesc{ServerStreams: true}, &testClientStream{messages: 10})
+
+		cancel()
+
+		require.Eventually(t, func() bool {
+			return logs.Len() == 1
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, codes.Canceled.String(), logs.All()[0].ContextMap()["grpc.code"])
+	})
+
+	t.Run("concurrent send and receive", func(t *testing.T) {
+		stream, logs := loggedStream(t, context.Background(), &grpc.StreamDesc{ClientStreams: true, ServerStreams: true},
+			&testClientStream{messages: 100, err: io.EOF})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				_ = stream.SendMsg(&pb.ReqSendMetrics{})
+			}
+		}()
+
+		for stream.RecvMsg(&pb.ResSendMetrics{}) == nil {
+		}
+
+		wg.Wait()
+
+		require.Equal(t, 1, logs.Len())
+		assert.EqualValues(t, 100, logs.All()[0].ContextMap()["grpc.received_messages"])
+	})
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	retriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_retries_total",
+		Help: "Total number of retried gRPC client calls by method and the code of the failed attempt.",
+	}, []string{"grpc_method", "grpc_code"})
+
+	callAttempts = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_call_attempts",
+		Help:    "Number of attempts made by gRPC client calls with retry policy.",
+		Buckets: []float64{1, 2, 3, 4, 5, 7, 10},
+	}, []string{"grpc_method"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(
+			retriesTotal,
+			callAttempts,
+		)
+	})
+}
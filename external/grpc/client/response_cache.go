@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/grpc/response_cache"
+)
+
+// ResponseCacheClientInterceptor skips the calls of the configured methods with the cached responses,
+// the cluster id is taken from the outgoing metadata so it goes after InjectClientMetadataInterceptor.
+func ResponseCacheClientInterceptor(c *response_cache.Cache) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		in, ok := req.(proto.Message)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		out, ok := reply.(proto.Message)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		md, _ := metadata.FromOutgoingContext(ctx)
+		clusterID := grpcC.MetadataValue(md, grpcC.ClusterIDKey)
+
+		if cached, err := c.Get(ctx, method, clusterID, in, out); err == nil && cached != nil {
+			return nil
+		}
+
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+
+		_ = c.Set(ctx, method, clusterID, in, out)
+		_ = c.InvalidateAfter(ctx, method, clusterID)
+
+		return nil
+	}
+}
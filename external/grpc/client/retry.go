@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/http_transport"
+)
+
+const (
+	idempotentKey = "idempotentCall"
+
+	DefaultBackoffMultiplier = 1.6
+	DefaultInitialBackoff    = 100 * time.Millisecond
+	DefaultMaxBackoff        = 5 * time.Second
+)
+
+var defaultRetryableCodes = map[codes.Code]struct{}{
+	codes.Unavailable: {},
+}
+
+// WithIdempotent marks the call as safe to retry on any of the policy retryable codes.
+func WithIdempotent(ctx context.Context) context.Context {
+	return http_transport.AddToContext(ctx, idempotentKey, true)
+}
+
+func isIdempotent(ctx context.Context) bool {
+	if marked, ok := http_transport.GetFromContext(ctx, idempotentKey).(bool); ok && marked {
+		return true
+	}
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	return ok && len(md.Get(grpcC.IdempotencyKey)) > 0
+}
+
+// RetryClientInterceptor retries failed unary calls with exponential backoff.
+// Calls which are not marked as idempotent (by policy, WithIdempotent or the idempotency metadata key)
+// are retried on UNAVAILABLE only, the attempts never outlive the call deadline.
+func RetryClientInterceptor(conf *configs.Retry) grpc.UnaryClientInterceptor {
+	if conf == nil {
+		conf = &configs.Retry{}
+	}
+
+	retryableCodes := make(map[*configs.RetryPolicy]map[codes.Code]struct{})
+
+	for _, policy := range append([]*configs.RetryPolicy{conf.Policy}, methodPolicies(conf)...) {
+		if policy == nil || len(policy.RetryableCodes) == 0 {
+			continue
+		}
+
+		retryableCodes[policy] = make(map[codes.Code]struct{}, len(policy.RetryableCodes))
+		for _, name := range policy.RetryableCodes {
+			if code, err := configs.ParseGrpcCode(name); err == nil {
+				retryableCodes[policy][code] = struct{}{}
+			}
+		}
+	}
+
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) (err error) {
+		policy := conf.PolicyFor(method)
+		if !conf.Enabled || policy == nil || policy.MaxAttempts <= 1 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		allowedCodes, ok := retryableCodes[policy]
+		if !ok {
+			allowedCodes = defaultRetryableCodes
+		}
+
+		idempotent := policy.Idempotent || isIdempotent(ctx)
+
+		var attempt uint
+		defer func() {
+			callAttempts.WithLabelValues(method).Observe(float64(attempt))
+		}()
+
+		for attempt = 1; ; attempt++ {
+			if err = invoker(ctx, method, req, reply, cc, opts...); err == nil {
+				return nil
+			}
+
+			code := status.Code(err)
+			if attempt >= policy.MaxAttempts || !isRetryable(code, allowedCodes, idempotent) {
+				return err
+			}
+
+			delay := retryBackoff(policy, attempt)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+				return err
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+
+			retriesTotal.WithLabelValues(method, code.String()).Inc()
+		}
+	}
+}
+
+func methodPolicies(conf *configs.Retry) (out []*configs.RetryPolicy) {
+	for _, policy := range conf.Methods {
+		out = append(out, policy)
+	}
+
+	return out
+}
+
+func isRetryable(code codes.Code, allowedCodes map[codes.Code]struct{}, idempotent bool) bool {
+	if _, ok := allowedCodes[code]; !ok {
+		return false
+	}
+
+	return idempotent || code == codes.Unavailable
+}
+
+func retryBackoff(policy *configs.RetryPolicy, attempt uint) time.Duration {
+	multiplier := policy.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = DefaultBackoffMultiplier
+	}
+
+	initial := policy.InitialBackoff
+	if initial <= 0 {
+		initial = DefaultInitialBackoff
+	}
+
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	backoff := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if backoff > float64(maxBackoff) {
+		backoff = float64(maxBackoff)
+	}
+
+	if policy.Jitter > 0 {
+		// nolint:gosec
+		backoff += backoff * policy.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(backoff)
+}
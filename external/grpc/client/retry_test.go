@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+func TestRetryClientInterceptor(t *testing.T) {
+	conf := &configs.Retry{
+		Enabled: true,
+		Policy: &configs.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+			RetryableCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+		},
+		Methods: map[string]*configs.RetryPolicy{
+			"services.GatewaySaverService/GetMetricsOffset": {
+				MaxAttempts:    2,
+				InitialBackoff: time.Millisecond,
+				RetryableCodes: []string{"RESOURCE_EXHAUSTED"},
+				Idempotent:     true,
+			},
+		},
+	}
+
+	var cases = []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		failures []codes.Code
+		want     codes.Code
+		calls    int
+	}{
+		{
+			name:     "1. unavailable is retried until success",
+			ctx:      context.Background(),
+			method:   "/services.GatewaySaverService/SendMetrics",
+			failures: []codes.Code{codes.Unavailable, codes.Unavailable},
+			want:     codes.OK,
+			calls:    3,
+		},
+		{
+			name:     "2. not idempotent call isn't retried on resource exhausted",
+			ctx:      context.Background(),
+			method:   "/services.GatewaySaverService/SendMetrics",
+			failures: []codes.Code{codes.ResourceExhausted},
+			want:     codes.ResourceExhausted,
+			calls:    1,
+		},
+		{
+			name:     "3. idempotent call is retried on resource exhausted",
+			ctx:      WithIdempotent(context.Background()),
+			method:   "/services.GatewaySaverService/SendMetrics",
+			failures: []codes.Code{codes.ResourceExhausted},
+			want:     codes.OK,
+			calls:    2,
+		},
+		{
+			name:     "4. method policy overrides attempts",
+			ctx:      context.Background(),
+			method:   "/services.GatewaySaverService/GetMetricsOffset",
+			failures: []codes.Code{codes.ResourceExhausted, codes.ResourceExhausted, codes.ResourceExhausted},
+			want:     codes.ResourceExhausted,
+			calls:    2,
+		},
+	}
+
+	interceptor := RetryClientInterceptor(conf)
+
+	for i := range cases {
+		tc := cases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				defer func() { calls++ }()
+
+				if calls < len(tc.failures) {
+					return status.Error(tc.failures[calls], "failed attempt")
+				}
+
+				return nil
+			}
+
+			err := interceptor(tc.ctx, tc.method, nil, nil, nil, invoker)
+			assert.Equal(t, tc.want, status.Code(err))
+			assert.Equal(t, tc.calls, calls)
+		})
+	}
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	var policy configs.RetryPolicy
+	assert.NoError(t, yaml.Unmarshal([]byte("maxAttempts: 3\nretryableCodes: [UNAVAILABLE]\n"), &policy))
+	assert.NoError(t, json.Unmarshal([]byte(`{"max_attempts":3}`), &policy))
+
+	assert.Equal(t, DefaultInitialBackoff, retryBackoff(&policy, 1))
+	assert.Equal(t, DefaultMaxBackoff, retryBackoff(&policy, 100))
+}
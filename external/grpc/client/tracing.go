@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	"github.com/dysnix/predictkube-libs/external/tracing"
+)
+
+// TracingUnaryClientInterceptor starts the client span of the call and propagates it with the W3C trace context.
+func TracingUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) (err error) {
+		ctx, span := tracing.StartGrpcClientSpan(ctx, method)
+		defer func() {
+			tracing.EndGrpcSpan(span, err)
+		}()
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func TracingStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		ctx, span := tracing.StartGrpcClientSpan(ctx, method)
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			tracing.EndGrpcSpan(span, err)
+			return nil, err
+		}
+
+		return &tracingClientStream{ClientStream: cs, span: span}, nil
+	}
+}
+
+type tracingClientStream struct {
+	grpc.ClientStream
+
+	once sync.Once
+	span trace.Span
+}
+
+func (s *tracingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF):
+		s.once.Do(func() { tracing.EndGrpcSpan(s.span, nil) })
+	default:
+		s.once.Do(func() { tracing.EndGrpcSpan(s.span, err) })
+	}
+
+	return err
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"errors"
+	"io"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+const (
+	DefaultMaxDecompressedSize = 64 << 20 // 64Mb
+)
+
+var (
+	ErrDecompressedSizeExceeded = errors.New("decompressed message size exceeds the limit")
+)
+
+// MaxDecompressedSize returns the decompressed message size limit of the compression config.
+func MaxDecompressedSize(conf *configs.Compression) uint64 {
+	if conf == nil || conf.MaxDecompressedSize == 0 {
+		return DefaultMaxDecompressedSize
+	}
+
+	return uint64(conf.MaxDecompressedSize)
+}
+
+// NewLimitedReader returns the decompressing reader which fails with ErrDecompressedSizeExceeded
+// when more than limit bytes are read, release is called once the reader is finished
+// to return the pooled decompressor back.
+func NewLimitedReader(r io.Reader, limit uint64, release func()) io.Reader {
+	return &limitedReader{r: r, left: limit, release: release}
+}
+
+type limitedReader struct {
+	r       io.Reader
+	left    uint64
+	release func()
+	err     error
+}
+
+func (l *limitedReader) Read(p []byte) (n int, err error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	if uint64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+
+	n, err = l.r.Read(p)
+	if uint64(n) > l.left {
+		n, err = int(l.left), ErrDecompressedSizeExceeded
+	}
+
+	l.left -= uint64(n)
+
+	if err != nil {
+		l.err = err
+
+		if l.release != nil {
+			l.release()
+			l.release = nil
+		}
+	}
+
+	return n, err
+}
@@ -0,0 +1,136 @@
+package grpc_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/protobuf/proto"
+
+	libsFaker "github.com/dysnix/predictkube-libs/external/faker"
+	"github.com/dysnix/predictkube-libs/external/grpc/brotli_compressor"
+	"github.com/dysnix/predictkube-libs/external/grpc/lz4_compressor"
+	"github.com/dysnix/predictkube-libs/external/grpc/snappy_compressor"
+	"github.com/dysnix/predictkube-libs/external/grpc/zstd_compressor"
+)
+
+var (
+	compressors = []string{
+		gzip.Name,
+		zstd_compressor.Name,
+		snappy_compressor.Name,
+		lz4_compressor.Name,
+		brotli_compressor.Name,
+	}
+
+	payloadsOnce sync.Once
+	payloads     [][]byte
+)
+
+// metricsPayloads returns the encoded fake pb.ReqSendMetrics messages of the metrics streams.
+func metricsPayloads(tb testing.TB) [][]byte {
+	payloadsOnce.Do(func() {
+		require.NoError(tb, libsFaker.MetricsGenerator(time.Hour))
+
+		for i := 0; i < 100; i++ {
+			req := &pb.ReqSendMetrics{}
+			require.NoError(tb, faker.FakeData(req))
+
+			data, err := proto.Marshal(req)
+			require.NoError(tb, err)
+
+			payloads = append(payloads, data)
+		}
+	})
+
+	return payloads
+}
+
+func compress(tb testing.TB, c encoding.Compressor, data []byte) []byte {
+	var buf bytes.Buffer
+
+	w, err := c.Compress(&buf)
+	require.NoError(tb, err)
+
+	_, err = w.Write(data)
+	require.NoError(tb, err)
+	require.NoError(tb, w.Close())
+
+	return buf.Bytes()
+}
+
+func decompress(tb testing.TB, c encoding.Compressor, data []byte) []byte {
+	r, err := c.Decompress(bytes.NewReader(data))
+	require.NoError(tb, err)
+
+	out, err := ioutil.ReadAll(r)
+	require.NoError(tb, err)
+
+	return out
+}
+
+func TestCompressors(t *testing.T) {
+	for _, name := range compressors {
+		t.Run(name, func(t *testing.T) {
+			c := encoding.GetCompressor(name)
+			require.NotNil(t, c)
+
+			for _, data := range metricsPayloads(t)[:10] {
+				assert.Equal(t, data, decompress(t, c, compress(t, c, data)))
+			}
+		})
+	}
+}
+
+// BenchmarkCompressors compares the compression ratio and the CPU cost of the registered
+// compressors on the metrics payloads, run it with:
+//
+//	go test -run=^$ -bench=Compressors -benchmem ./external/grpc/
+func BenchmarkCompressors(b *testing.B) {
+	data := metricsPayloads(b)
+
+	var total int
+	for _, payload := range data {
+		total += len(payload)
+	}
+
+	for _, name := range compressors {
+		c := encoding.GetCompressor(name)
+
+		compressed := make([][]byte, len(data))
+		var compressedTotal int
+		for i, payload := range data {
+			compressed[i] = compress(b, c, payload)
+			compressedTotal += len(compressed[i])
+		}
+
+		b.Run(fmt.Sprintf("%s/compress", name), func(b *testing.B) {
+			b.SetBytes(int64(total / len(data)))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				compress(b, c, data[i%len(data)])
+			}
+
+			b.ReportMetric(float64(total)/float64(compressedTotal), "ratio")
+		})
+
+		b.Run(fmt.Sprintf("%s/decompress", name), func(b *testing.B) {
+			b.SetBytes(int64(total / len(data)))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				decompress(b, c, compressed[i%len(compressed)])
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Package compressors registers all the supported gRPC compressors
+// and tunes them with the compression config.
+package compressors
+
+import (
+	stdgzip "compress/gzip"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+	"github.com/dysnix/predictkube-libs/external/grpc/brotli_compressor"
+	"github.com/dysnix/predictkube-libs/external/grpc/lz4_compressor"
+	"github.com/dysnix/predictkube-libs/external/grpc/snappy_compressor"
+	"github.com/dysnix/predictkube-libs/external/grpc/zstd_compressor"
+)
+
+// gzipLevel guards gzip.SetLevel, it isn't thread-safe and the running calls use the level.
+var gzipLevel sync.Once
+
+// Configure tunes all the compressors used by the compression config and accepted by the servers,
+// so the decompressed size limit and the dictionaries cover every accepted encoding. The level is
+// applied to the primary type only and the other types use their default levels, the gzip level
+// is set once by the first config with it.
+func Configure(conf *configs.Compression) (err error) {
+	for _, t := range types(conf) {
+		typeConf := *conf
+		if t != conf.Type {
+			typeConf.Level = 0
+		}
+
+		switch t {
+		case enums.Gzip:
+			if typeConf.Level != 0 {
+				err = setGzipLevel(typeConf.Level)
+			}
+		case enums.Zstd:
+			err = zstd_compressor.Configure(&typeConf)
+		case enums.Snappy:
+			err = snappy_compressor.Configure(&typeConf)
+		case enums.LZ4:
+			err = lz4_compressor.Configure(&typeConf)
+		case enums.Brotli:
+			err = brotli_compressor.Configure(&typeConf)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// types returns the compression types of the client calls and the other registered types accepted by the servers.
+func types(conf *configs.Compression) []enums.CompressionType {
+	out := conf.Types()
+
+	seen := map[enums.CompressionType]bool{enums.None: true}
+	for _, t := range out {
+		seen[t] = true
+	}
+
+	for _, t := range enums.CompressionTypeValues() {
+		if !seen[t] && conf.Accepts(t) && len(Name(t)) > 0 {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+
+	return out
+}
+
+func setGzipLevel(level int) (err error) {
+	if level < stdgzip.HuffmanOnly || level > stdgzip.BestCompression {
+		return fmt.Errorf("grpc: invalid gzip compression level: %d", level)
+	}
+
+	gzipLevel.Do(func() {
+		err = gzip.SetLevel(level)
+	})
+
+	return err
+}
+
+// Name returns the registered gRPC encoding name of the compression type
+// or an empty string if the type isn't compressed.
+func Name(t enums.CompressionType) string {
+	if t == enums.None || encoding.GetCompressor(t.String()) == nil {
+		return ""
+	}
+
+	return t.String()
+}
+
+// TypeOf returns the compression type of the gRPC encoding name.
+func TypeOf(name string) (enums.CompressionType, error) {
+	if len(name) == 0 || name == encoding.Identity {
+		return enums.None, nil
+	}
+
+	return enums.ParseCompressionTypeString(name)
+}
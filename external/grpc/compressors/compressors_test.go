@@ -0,0 +1,85 @@
+package compressors
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+	"github.com/dysnix/predictkube-libs/external/grpc/zstd_compressor"
+)
+
+func TestConfigure(t *testing.T) {
+	var cases = []struct {
+		name    string
+		conf    *configs.Compression
+		wantErr bool
+	}{
+		{
+			name: "level of the primary type with the method overrides",
+			conf: &configs.Compression{
+				Type:  enums.Zstd,
+				Level: 19,
+				Methods: map[string]enums.CompressionType{
+					"services.GatewaySaverService/SendMetrics": enums.LZ4,
+					"services.OtherService":                    enums.Gzip,
+				},
+			},
+		},
+		{
+			name: "invalid level of the primary type",
+			conf: &configs.Compression{
+				Type:    enums.Gzip,
+				Level:   19,
+				Methods: map[string]enums.CompressionType{"services.OtherService": enums.Zstd},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Configure(c.conf)
+			if c.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// restore the default levels for the other tests
+	assert.NoError(t, Configure(&configs.Compression{Type: enums.Zstd}))
+}
+
+func TestConfigureAcceptedEncodings(t *testing.T) {
+	payload := []byte(strings.Repeat(`{"cluster_id":"bsc-1","metric":"cpu","value":0.42}`, 1000))
+
+	var compressed bytes.Buffer
+
+	w, err := encoding.GetCompressor(zstd_compressor.Name).Compress(&compressed)
+	require.NoError(t, err)
+
+	_, err = w.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// the server doesn't compress its responses, but the accepted requests are still limited
+	require.NoError(t, Configure(&configs.Compression{Type: enums.None, MaxDecompressedSize: 1024}))
+	defer func() {
+		assert.NoError(t, Configure(&configs.Compression{Type: enums.Zstd}))
+	}()
+
+	r, err := encoding.GetCompressor(zstd_compressor.Name).Decompress(&compressed)
+	require.NoError(t, err)
+
+	out, err := ioutil.ReadAll(r)
+	assert.Error(t, err)
+	assert.LessOrEqual(t, len(out), 1024)
+}
@@ -1,8 +1,9 @@
 package grpc
 
 const (
-	ClusterIDKey = "x-auth-cluster-id"
-	NameKey      = "x-auth-client-name"
-	TokenKey     = "x-auth-token"
-	APIKey       = "x-auth-api-key"
+	ClusterIDKey   = "x-auth-cluster-id"
+	NameKey        = "x-auth-client-name"
+	TokenKey       = "x-auth-token"
+	APIKey         = "x-auth-api-key"
+	IdempotencyKey = "x-idempotency-key"
 )
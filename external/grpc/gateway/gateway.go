@@ -0,0 +1,292 @@
+// Package gateway exposes the unary gRPC methods as the JSON-over-HTTP routes of the fasthttp server.
+package gateway
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+const bodyAll = "*"
+
+// the path variables of the http rule templates, e.g. {cluster_id} or {name=projects/*}
+var templateVar = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?}`)
+
+// pathParam is the field bound to the path variable, the field value is rebuilt from the literal
+// segments of the variable pattern and the router parameters matching its wildcards.
+type pathParam struct {
+	field    string
+	segments []pathSegment
+}
+
+// pathSegment is either the literal segment or the router parameter of the wildcard.
+type pathSegment struct {
+	literal string
+	param   string
+}
+
+func (p pathParam) value(ctx *fasthttp.RequestCtx) string {
+	parts := make([]string, 0, len(p.segments))
+	for _, seg := range p.segments {
+		if len(seg.param) == 0 {
+			parts = append(parts, seg.literal)
+			continue
+		}
+
+		value, _ := ctx.UserValue(seg.param).(string)
+		parts = append(parts, value)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// route is the HTTP binding of the unary method.
+type route struct {
+	httpMethod string
+	path       string
+	params     []pathParam
+	body       string
+	fullMethod string
+	input      protoreflect.MessageType
+	output     protoreflect.MessageType
+}
+
+// Gateway calls the gRPC methods of the connection with the JSON requests.
+type Gateway struct {
+	conn      grpc.ClientConnInterface
+	routes    []*route
+	marshal   protojson.MarshalOptions
+	unmarshal protojson.UnmarshalOptions
+}
+
+// New returns the gateway of the unary methods of the services, the methods with the google.api.http
+// annotations are bound by them, the others are bound as POST /package.Service/Method with the body.
+func New(conn grpc.ClientConnInterface, services ...string) (*Gateway, error) {
+	g := &Gateway{
+		conn:      conn,
+		marshal:   protojson.MarshalOptions{EmitUnpopulated: true},
+		unmarshal: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}
+
+	for _, name := range services {
+		desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("find service %s: %w", name, err)
+		}
+
+		service, ok := desc.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("%s isn't a service", name)
+		}
+
+		for i := 0; i < service.Methods().Len(); i++ {
+			routes, err := methodRoutes(service.Methods().Get(i))
+			if err != nil {
+				return nil, err
+			}
+
+			g.routes = append(g.routes, routes...)
+		}
+	}
+
+	return g, nil
+}
+
+// ServiceNames returns the names of the services registered on the server.
+func ServiceNames(s *grpc.Server) (out []string) {
+	for name := range s.GetServiceInfo() {
+		out = append(out, name)
+	}
+
+	sort.Strings(out)
+
+	return out
+}
+
+// Routes returns the routes of the gateway for the SetRoutes option of the http server, the paths
+// bound to several HTTP methods get the route of any method dispatching the requests by the method.
+func (g *Gateway) Routes(group string) map[string]map[string]*configs.Route {
+	byPath := make(map[string]map[string]fasthttp.RequestHandler)
+
+	for _, r := range g.routes {
+		if byPath[r.path] == nil {
+			byPath[r.path] = make(map[string]fasthttp.RequestHandler)
+		}
+
+		byPath[r.path][r.httpMethod] = g.handler(r)
+	}
+
+	out := make(map[string]*configs.Route, len(byPath))
+	for path, handlers := range byPath {
+		if len(handlers) == 1 {
+			for method, handler := range handlers {
+				out[path] = &configs.Route{Method: method, RequestHandler: handler}
+			}
+
+			continue
+		}
+
+		out[path] = &configs.Route{RequestHandler: dispatch(handlers)}
+	}
+
+	return map[string]map[string]*configs.Route{group: out}
+}
+
+func dispatch(handlers map[string]fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if handler, ok := handlers[string(ctx.Method())]; ok {
+			handler(ctx)
+			return
+		}
+
+		ctx.Error(fasthttp.StatusMessage(fasthttp.StatusMethodNotAllowed), fasthttp.StatusMethodNotAllowed)
+	}
+}
+
+func methodRoutes(md protoreflect.MethodDescriptor) ([]*route, error) {
+	// the streams aren't supported by the gateway
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, nil
+	}
+
+	input, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+
+	fullMethod := fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+
+	var rule *annotations.HttpRule
+	if options, ok := md.Options().(*descriptorpb.MethodOptions); ok && options != nil {
+		rule, _ = proto.GetExtension(options, annotations.E_Http).(*annotations.HttpRule)
+	}
+
+	if rule == nil {
+		return []*route{{
+			httpMethod: fasthttp.MethodPost,
+			path:       fullMethod,
+			body:       bodyAll,
+			fullMethod: fullMethod,
+			input:      input,
+			output:     output,
+		}}, nil
+	}
+
+	var out []*route
+	for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+		httpMethod, template := rulePattern(r)
+		if len(template) == 0 {
+			return nil, fmt.Errorf("method %s has the http rule without pattern", fullMethod)
+		}
+
+		path, params := routerPath(template)
+
+		for _, param := range params {
+			if _, err := fieldPath(md.Input(), param.field); err != nil {
+				return nil, fmt.Errorf("method %s path: %w", fullMethod, err)
+			}
+		}
+
+		out = append(out, &route{
+			httpMethod: httpMethod,
+			path:       path,
+			params:     params,
+			body:       r.GetBody(),
+			fullMethod: fullMethod,
+			input:      input,
+			output:     output,
+		})
+	}
+
+	return out, nil
+}
+
+func rulePattern(r *annotations.HttpRule) (method, template string) {
+	switch p := r.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return fasthttp.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		return fasthttp.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		return fasthttp.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		return fasthttp.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		return fasthttp.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		return strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	default:
+		return "", ""
+	}
+}
+
+// routerPath converts the http rule template to the router path, the literal segments of the variable
+// patterns are kept in the path and their wildcards become the router parameters, "**" is the catch-all one.
+// The variables with the single wildcard use the field name as the parameter name.
+func routerPath(template string) (path string, params []pathParam) {
+	path = templateVar.ReplaceAllStringFunc(template, func(v string) string {
+		m := templateVar.FindStringSubmatch(v)
+
+		pattern := strings.TrimPrefix(m[2], "=")
+		if len(pattern) == 0 {
+			pattern = "*"
+		}
+
+		segments := strings.Split(pattern, "/")
+
+		wildcards := 0
+		for _, seg := range segments {
+			if seg == "*" || seg == "**" {
+				wildcards++
+			}
+		}
+
+		param := pathParam{field: m[1]}
+		parts := make([]string, 0, len(segments))
+
+		for _, seg := range segments {
+			if seg != "*" && seg != "**" {
+				param.segments = append(param.segments, pathSegment{literal: seg})
+				parts = append(parts, seg)
+
+				continue
+			}
+
+			name := m[1]
+			if wildcards > 1 {
+				name = fmt.Sprintf("%s.%d", m[1], len(param.segments))
+			}
+
+			param.segments = append(param.segments, pathSegment{param: name})
+
+			if seg == "**" {
+				parts = append(parts, "{"+name+":*}")
+			} else {
+				parts = append(parts, "{"+name+"}")
+			}
+		}
+
+		params = append(params, param)
+
+		return strings.Join(parts, "/")
+	})
+
+	return path, params
+}
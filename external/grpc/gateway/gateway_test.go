@@ -0,0 +1,333 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/dysnix/predictkube-libs/external/base"
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/grpc/grpctest"
+	"github.com/dysnix/predictkube-libs/external/grpc/server"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+)
+
+const clustersService = "gatewaytest.Clusters"
+
+type gatewaySaver struct {
+	pb.UnimplementedGatewaySaverServiceServer
+}
+
+func (*gatewaySaver) GetMetricsOffset(_ context.Context, req *pb.ReqGetMetricsOffset) (*pb.ResGetMetricsOffset, error) {
+	if len(req.GetHeader().GetClusterId()) == 0 {
+		return nil, status.Error(codes.NotFound, "cluster not found")
+	}
+
+	return &pb.ResGetMetricsOffset{CurrentOffset: 42}, nil
+}
+
+type serverConfigs struct {
+	conf *configs.Single
+}
+
+func (c serverConfigs) GetServerConfigs() *configs.Single {
+	return c.conf
+}
+
+// registerClustersService registers the service with the http rules, the handlers
+// echo the name of the request and the called method.
+func registerClustersService(t *testing.T) grpc.ServiceDesc {
+	t.Helper()
+
+	rules := []struct {
+		method string
+		rule   *annotations.HttpRule
+	}{
+		{"GetCluster", &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=clusters/*}"}}},
+		{"DeleteCluster", &annotations.HttpRule{Pattern: &annotations.HttpRule_Delete{Delete: "/v1/{name=clusters/*}"}}},
+		{"GetNode", &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=clusters/*/nodes/*}"}}},
+		{"GetFile", &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=files/**}"}}},
+	}
+
+	stringField := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+	}
+
+	service := &descriptorpb.ServiceDescriptorProto{Name: proto.String("Clusters")}
+	for _, r := range rules {
+		options := &descriptorpb.MethodOptions{}
+		proto.SetExtension(options, annotations.E_Http, r.rule)
+
+		service.Method = append(service.Method, &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(r.method),
+			InputType:  proto.String(".gatewaytest.Request"),
+			OutputType: proto.String(".gatewaytest.Response"),
+			Options:    options,
+		})
+	}
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("gatewaytest/clusters.proto"),
+		Package: proto.String("gatewaytest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Request"), Field: []*descriptorpb.FieldDescriptorProto{stringField("name", 1)}},
+			{Name: proto.String("Response"), Field: []*descriptorpb.FieldDescriptorProto{stringField("name", 1), stringField("method", 2)}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{service},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+
+	if _, err = protoregistry.GlobalFiles.FindFileByPath(file.Path()); err != nil {
+		require.NoError(t, protoregistry.GlobalFiles.RegisterFile(file))
+
+		for i := 0; i < file.Messages().Len(); i++ {
+			require.NoError(t, protoregistry.GlobalTypes.RegisterMessage(dynamicpb.NewMessageType(file.Messages().Get(i))))
+		}
+	}
+
+	reqDesc, respDesc := file.Messages().Get(0), file.Messages().Get(1)
+
+	desc := grpc.ServiceDesc{
+		ServiceName: clustersService,
+		HandlerType: (*interface{})(nil),
+	}
+
+	for _, r := range rules {
+		method := r.method
+
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: method,
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := dynamicpb.NewMessage(reqDesc)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+
+				handler := func(_ context.Context, req interface{}) (interface{}, error) {
+					resp := dynamicpb.NewMessage(respDesc)
+					resp.Set(respDesc.Fields().ByName("name"), req.(protoreflect.ProtoMessage).ProtoReflect().Get(reqDesc.Fields().ByName("name")))
+					resp.Set(respDesc.Fields().ByName("method"), protoreflect.ValueOfString(method))
+
+					return resp, nil
+				}
+
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/" + clustersService + "/" + method}, handler)
+			},
+		})
+	}
+
+	return desc
+}
+
+func TestGateway(t *testing.T) {
+	clusters := registerClustersService(t)
+
+	s := grpctest.New(t, func(s *grpc.Server) {
+		pb.RegisterGatewaySaverServiceServer(s, &gatewaySaver{})
+		s.RegisterService(&clusters, struct{}{})
+	}, grpctest.WithServerInterceptors(server.InjectClientMetadataInterceptor()))
+
+	g, err := New(s.Conn, ServiceNames(s.Server)...)
+	require.NoError(t, err)
+
+	routes := g.Routes(base.Root)
+	for group, groupRoutes := range g.Routes("/api") {
+		routes[group] = groupRoutes
+	}
+
+	srv, err := base.NewHttpServer(
+		configs.SetServerConfigs(serverConfigs{conf: &configs.Single{
+			Buffer:       &configs.Buffer{},
+			TCPKeepalive: &configs.TCPKeepalive{},
+		}}),
+		configs.SetRoutes(routes),
+	)
+	require.NoError(t, err)
+
+	var cases = []struct {
+		name       string
+		method     string
+		path       string
+		headers    map[string]string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "auth headers are the metadata",
+			method:     fasthttp.MethodPost,
+			path:       "/api/services.GatewaySaverService/GetMetricsOffset",
+			headers:    map[string]string{"X-Auth-Cluster-Id": "bsc-1"},
+			body:       `{}`,
+			wantStatus: fasthttp.StatusOK,
+			wantBody:   `{"currentOffset":"42"}`,
+		},
+		{
+			name:       "body is the request",
+			method:     fasthttp.MethodPost,
+			path:       "/services.GatewaySaverService/GetMetricsOffset",
+			body:       `{"header":{"clusterId":"eth-1"}}`,
+			wantStatus: fasthttp.StatusOK,
+			wantBody:   `{"currentOffset":"42"}`,
+		},
+		{
+			name:       "status code is converted",
+			method:     fasthttp.MethodPost,
+			path:       "/services.GatewaySaverService/GetMetricsOffset",
+			wantStatus: fasthttp.StatusNotFound,
+			wantBody:   `{"code":5,"message":"cluster not found","details":[]}`,
+		},
+		{
+			name:       "invalid body",
+			method:     fasthttp.MethodPost,
+			path:       "/services.GatewaySaverService/GetMetricsOffset",
+			body:       `{"header":`,
+			wantStatus: fasthttp.StatusBadRequest,
+		},
+		{
+			name:       "unimplemented method",
+			method:     fasthttp.MethodPost,
+			path:       "/services.GatewaySaverService/SendMetrics",
+			body:       `{}`,
+			wantStatus: fasthttp.StatusNotImplemented,
+		},
+		{
+			name:       "literal segments are kept in the field",
+			method:     fasthttp.MethodGet,
+			path:       "/v1/clusters/bsc-1",
+			wantStatus: fasthttp.StatusOK,
+			wantBody:   `{"name":"clusters/bsc-1","method":"GetCluster"}`,
+		},
+		{
+			name:       "unknown query parameters are ignored",
+			method:     fasthttp.MethodGet,
+			path:       "/v1/clusters/bsc-1?utm_source=mail&_=1700000000",
+			wantStatus: fasthttp.StatusOK,
+			wantBody:   `{"name":"clusters/bsc-1","method":"GetCluster"}`,
+		},
+		{
+			name:       "path of several methods is dispatched by the method",
+			method:     fasthttp.MethodDelete,
+			path:       "/v1/clusters/bsc-1",
+			wantStatus: fasthttp.StatusOK,
+			wantBody:   `{"name":"clusters/bsc-1","method":"DeleteCluster"}`,
+		},
+		{
+			name:       "method isn't bound to the path",
+			method:     fasthttp.MethodPut,
+			path:       "/v1/clusters/bsc-1",
+			wantStatus: fasthttp.StatusMethodNotAllowed,
+		},
+		{
+			name:       "several wildcards",
+			method:     fasthttp.MethodGet,
+			path:       "/v1/clusters/bsc-1/nodes/node-1",
+			wantStatus: fasthttp.StatusOK,
+			wantBody:   `{"name":"clusters/bsc-1/nodes/node-1","method":"GetNode"}`,
+		},
+		{
+			name:       "catch-all wildcard",
+			method:     fasthttp.MethodGet,
+			path:       "/v1/files/metrics/bsc-1.json",
+			wantStatus: fasthttp.StatusOK,
+			wantBody:   `{"name":"files/metrics/bsc-1.json","method":"GetFile"}`,
+		},
+		{
+			name:       "literal segment doesn't match",
+			method:     fasthttp.MethodGet,
+			path:       "/v1/projects/bsc-1",
+			wantStatus: fasthttp.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(c.method)
+			ctx.Request.SetRequestURI(c.path)
+			ctx.Request.SetBodyString(c.body)
+
+			for key, value := range c.headers {
+				ctx.Request.Header.Set(key, value)
+			}
+
+			srv.Handler()(ctx)
+
+			assert.Equal(t, c.wantStatus, ctx.Response.StatusCode())
+			if len(c.wantBody) > 0 {
+				assert.JSONEq(t, c.wantBody, string(ctx.Response.Body()))
+			}
+		})
+	}
+}
+
+func TestRouterPath(t *testing.T) {
+	var cases = []struct {
+		template   string
+		wantPath   string
+		wantFields []string
+	}{
+		{
+			template: "/v1/metrics",
+			wantPath: "/v1/metrics",
+		},
+		{
+			template:   "/v1/clusters/{header.cluster_id}/offset",
+			wantPath:   "/v1/clusters/{header.cluster_id}/offset",
+			wantFields: []string{"header.cluster_id"},
+		},
+		{
+			template:   "/v1/{name=clusters/*}",
+			wantPath:   "/v1/clusters/{name}",
+			wantFields: []string{"name"},
+		},
+		{
+			template:   "/v1/{name=clusters/*/nodes/*}",
+			wantPath:   "/v1/clusters/{name.1}/nodes/{name.3}",
+			wantFields: []string{"name"},
+		},
+		{
+			template:   "/v1/files/{path=**}",
+			wantPath:   "/v1/files/{path:*}",
+			wantFields: []string{"path"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.template, func(t *testing.T) {
+			path, params := routerPath(c.template)
+
+			var fields []string
+			for _, param := range params {
+				fields = append(fields, param.field)
+			}
+
+			assert.Equal(t, c.wantPath, path)
+			assert.Equal(t, c.wantFields, fields)
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/tracing"
+)
+
+var errUnknownField = errors.New("unknown field")
+
+const (
+	authHeaderPrefix     = "X-Auth-"
+	metadataHeaderPrefix = "Grpc-Metadata-"
+	bearerPrefix         = "Bearer "
+)
+
+func (g *Gateway) handler(r *route) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		req := r.input.New()
+
+		if err := g.decodeRequest(ctx, r, req); err != nil {
+			g.writeError(ctx, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+
+		callCtx := metadata.NewOutgoingContext(tracing.ContextWithSpan(ctx), requestMetadata(ctx))
+
+		var header metadata.MD
+		resp := r.output.New().Interface()
+
+		if err := g.conn.Invoke(callCtx, r.fullMethod, req.Interface(), resp, grpc.Header(&header)); err != nil {
+			g.writeError(ctx, err)
+			return
+		}
+
+		data, err := g.marshal.Marshal(resp)
+		if err != nil {
+			g.writeError(ctx, status.Errorf(codes.Internal, "encode response: %v", err))
+			return
+		}
+
+		writeMetadata(ctx, header)
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.SetContentType("application/json")
+		ctx.SetBody(data)
+	}
+}
+
+func (g *Gateway) decodeRequest(ctx *fasthttp.RequestCtx, r *route, req protoreflect.Message) (err error) {
+	if body := ctx.PostBody(); len(body) > 0 && len(r.body) > 0 {
+		target := req
+		if r.body != bodyAll {
+			fields, err := fieldPath(req.Descriptor(), r.body)
+			if err != nil {
+				return err
+			}
+
+			target = mutableParent(req, fields)
+			if fd := fields[len(fields)-1]; fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+				target = target.Mutable(fd).Message()
+			} else {
+				return fmt.Errorf("body field %q isn't a message", r.body)
+			}
+		}
+
+		if err = g.unmarshal.Unmarshal(body, target.Interface()); err != nil {
+			return fmt.Errorf("decode body: %w", err)
+		}
+	}
+
+	for _, param := range r.params {
+		if err = setField(req, param.field, param.value(ctx)); err != nil {
+			return err
+		}
+	}
+
+	// the query parameters fill the fields which are not bound to the body, the unknown ones
+	// are ignored, as the clients add them for the tracking or the cache busting
+	if r.body != bodyAll {
+		ctx.QueryArgs().VisitAll(func(key, value []byte) {
+			if err == nil {
+				if err = setField(req, string(key), string(value)); errors.Is(err, errUnknownField) {
+					err = nil
+				}
+			}
+		})
+	}
+
+	return err
+}
+
+// requestMetadata translates the auth headers to the x-auth-* metadata, the bearer token of
+// the Authorization header is the token key.
+func requestMetadata(ctx *fasthttp.RequestCtx) metadata.MD {
+	md := metadata.MD{}
+
+	ctx.Request.Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+
+		switch {
+		case strings.HasPrefix(name, authHeaderPrefix):
+			md.Append(strings.ToLower(name), string(value))
+		case name == fasthttp.HeaderAuthorization && strings.HasPrefix(string(value), bearerPrefix):
+			md.Set(grpcC.TokenKey, strings.TrimPrefix(string(value), bearerPrefix))
+		case strings.EqualFold(name, grpcC.IdempotencyKey):
+			md.Set(grpcC.IdempotencyKey, string(value))
+		}
+	})
+
+	return md
+}
+
+func writeMetadata(ctx *fasthttp.RequestCtx, md metadata.MD) {
+	for key, values := range md {
+		for _, value := range values {
+			ctx.Response.Header.Add(metadataHeaderPrefix+key, value)
+		}
+	}
+}
+
+func (g *Gateway) writeError(ctx *fasthttp.RequestCtx, err error) {
+	st := status.Convert(err)
+
+	data, err := g.marshal.Marshal(st.Proto())
+	if err != nil {
+		data = []byte(fmt.Sprintf(`{"code":%d,"message":%q}`, st.Code(), st.Message()))
+	}
+
+	ctx.SetStatusCode(HTTPStatusFromCode(st.Code()))
+	ctx.SetContentType("application/json")
+	ctx.SetBody(data)
+}
+
+func fieldPath(desc protoreflect.MessageDescriptor, path string) (fields []protoreflect.FieldDescriptor, err error) {
+	names := strings.Split(path, ".")
+
+	for i, name := range names {
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = desc.Fields().ByJSONName(name)
+		}
+
+		if fd == nil {
+			return nil, fmt.Errorf("%w: message %s has no field %q", errUnknownField, desc.FullName(), name)
+		}
+
+		fields = append(fields, fd)
+
+		if i < len(names)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return nil, fmt.Errorf("field %q of message %s is not a message", name, desc.FullName())
+			}
+
+			desc = fd.Message()
+		}
+	}
+
+	return fields, nil
+}
+
+func mutableParent(msg protoreflect.Message, fields []protoreflect.FieldDescriptor) protoreflect.Message {
+	for _, fd := range fields[:len(fields)-1] {
+		msg = msg.Mutable(fd).Message()
+	}
+
+	return msg
+}
+
+// setField sets the scalar field of the path from the string value, the values of the lists are appended.
+func setField(msg protoreflect.Message, path, value string) error {
+	fields, err := fieldPath(msg.Descriptor(), path)
+	if err != nil {
+		return err
+	}
+
+	fd := fields[len(fields)-1]
+	if fd.IsMap() || (fd.Message() != nil) {
+		return fmt.Errorf("field %q isn't a scalar", path)
+	}
+
+	v, err := parseScalar(fd, value)
+	if err != nil {
+		return fmt.Errorf("field %q: %w", path, err)
+	}
+
+	parent := mutableParent(msg, fields)
+	if fd.IsList() {
+		parent.Mutable(fd).List().Append(v)
+		return nil
+	}
+
+	parent.Set(fd, v)
+
+	return nil
+}
+
+func parseScalar(fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(value)
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported kind %s", fd.Kind())
+	}
+}
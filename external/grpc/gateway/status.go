@@ -0,0 +1,58 @@
+package gateway
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/dysnix/predictkube-libs/external/app_errors"
+)
+
+// statusClientClosedRequest is the nginx status of the requests cancelled by the clients.
+const statusClientClosedRequest = 499
+
+// HTTPStatusFromCode returns the HTTP status code of the gRPC status code.
+func HTTPStatusFromCode(code codes.Code) int {
+	if code == codes.OK {
+		return http.StatusOK
+	}
+
+	return app_errors.KindOfCode(code).HTTPStatus()
+}
+
+// CodeFromHTTPStatus returns the gRPC status code of the HTTP status code, the statuses
+// without the gRPC counterpart are Unknown.
+func CodeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case statusClientClosedRequest:
+		return codes.Canceled
+	case http.StatusInternalServerError:
+		return codes.Internal
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	if httpStatus >= http.StatusOK && httpStatus < http.StatusMultipleChoices {
+		return codes.OK
+	}
+
+	return codes.Unknown
+}
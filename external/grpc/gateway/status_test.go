@@ -0,0 +1,77 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	var cases = []struct {
+		code       codes.Code
+		wantStatus int
+	}{
+		{codes.OK, http.StatusOK},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.PermissionDenied, http.StatusForbidden},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.AlreadyExists, http.StatusConflict},
+		{codes.ResourceExhausted, http.StatusTooManyRequests},
+		{codes.Canceled, statusClientClosedRequest},
+		{codes.Unimplemented, http.StatusNotImplemented},
+		{codes.Unavailable, http.StatusServiceUnavailable},
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout},
+		{codes.Internal, http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		t.Run(c.code.String(), func(t *testing.T) {
+			assert.Equal(t, c.wantStatus, HTTPStatusFromCode(c.code))
+		})
+	}
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	var cases = []struct {
+		httpStatus int
+		wantCode   codes.Code
+	}{
+		{http.StatusOK, codes.OK},
+		{http.StatusNoContent, codes.OK},
+		{http.StatusBadRequest, codes.InvalidArgument},
+		{http.StatusUnauthorized, codes.Unauthenticated},
+		{http.StatusForbidden, codes.PermissionDenied},
+		{http.StatusNotFound, codes.NotFound},
+		{http.StatusConflict, codes.AlreadyExists},
+		{http.StatusPreconditionFailed, codes.FailedPrecondition},
+		{http.StatusTooManyRequests, codes.ResourceExhausted},
+		{statusClientClosedRequest, codes.Canceled},
+		{http.StatusInternalServerError, codes.Internal},
+		{http.StatusNotImplemented, codes.Unimplemented},
+		{http.StatusBadGateway, codes.Unavailable},
+		{http.StatusServiceUnavailable, codes.Unavailable},
+		{http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{http.StatusMovedPermanently, codes.Unknown},
+		{http.StatusTeapot, codes.Unknown},
+	}
+
+	for _, c := range cases {
+		t.Run(http.StatusText(c.httpStatus), func(t *testing.T) {
+			assert.Equal(t, c.wantCode, CodeFromHTTPStatus(c.httpStatus))
+		})
+	}
+}
+
+// the codes of the kinds are converted back to themselves
+func TestStatusRoundTrip(t *testing.T) {
+	for _, code := range []codes.Code{
+		codes.OK, codes.InvalidArgument, codes.Unauthenticated, codes.PermissionDenied, codes.NotFound,
+		codes.AlreadyExists, codes.ResourceExhausted, codes.Canceled, codes.Unimplemented,
+		codes.Unavailable, codes.DeadlineExceeded, codes.Internal,
+	} {
+		assert.Equal(t, code, CodeFromHTTPStatus(HTTPStatusFromCode(code)), code.String())
+	}
+}
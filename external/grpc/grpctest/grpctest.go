@@ -0,0 +1,209 @@
+// Package grpctest starts the in-memory gRPC servers with the library interceptors for the tests.
+package grpctest
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/grpc/client"
+	"github.com/dysnix/predictkube-libs/external/grpc/server"
+)
+
+const (
+	bufSize = 1 << 20
+
+	target = "bufnet"
+)
+
+type options struct {
+	conf               *configs.GRPC
+	baseConf           *configs.Base
+	clientConf         *configs.Client
+	logger             *zap.SugaredLogger
+	serverInterceptors []grpc.UnaryServerInterceptor
+	clientInterceptors []grpc.UnaryClientInterceptor
+	md                 metadata.MD
+}
+
+type Option func(*options)
+
+// WithConfigs sets the configs of SetGrpcServerOptions and SetGrpcClientOptions,
+// the connection is always insecure.
+func WithConfigs(conf *configs.GRPC, baseConf *configs.Base) Option {
+	return func(o *options) {
+		o.conf = conf
+		o.baseConf = baseConf
+	}
+}
+
+// WithClient adds the client options of the client configs, the credentials need to be insecure.
+func WithClient(conf *configs.Client) Option {
+	return func(o *options) {
+		o.clientConf = conf
+	}
+}
+
+// WithLogger sets the logger of the server and the client calls logging.
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithServerInterceptors adds the internal interceptors of the server chain.
+func WithServerInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) {
+		o.serverInterceptors = append(o.serverInterceptors, interceptors...)
+	}
+}
+
+// WithClientInterceptors adds the internal interceptors of the client chain.
+func WithClientInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(o *options) {
+		o.clientInterceptors = append(o.clientInterceptors, interceptors...)
+	}
+}
+
+// WithMetadata adds the metadata to all the calls of the client.
+func WithMetadata(kv ...string) Option {
+	return func(o *options) {
+		o.md = metadata.Join(o.md, metadata.Pairs(kv...))
+	}
+}
+
+func WithClusterID(clusterID string) Option {
+	return WithMetadata(grpcC.ClusterIDKey, clusterID)
+}
+
+func WithToken(token string) Option {
+	return WithMetadata(grpcC.TokenKey, token)
+}
+
+// Server is the gRPC server listening on the in-memory connection with the connected client.
+type Server struct {
+	*grpc.Server
+	Conn *grpc.ClientConn
+}
+
+// New starts the server with the services of register and the full interceptor chain of the library,
+// the server and the client are stopped by the test cleanup.
+func New(t testing.TB, register func(s *grpc.Server), opts ...Option) *Server {
+	t.Helper()
+
+	o := &options{
+		conf:     &configs.GRPC{},
+		baseConf: &configs.Base{},
+	}
+
+	for _, op := range opts {
+		op(o)
+	}
+
+	conf := *o.conf
+	conn := configs.Connection{}
+	if conf.Conn != nil {
+		conn = *conf.Conn
+	}
+
+	conn.Insecure = true
+	conf.Conn = &conn
+	conf.TLS = nil
+	conf.LoadBalancing = nil
+
+	serverOptions, err := server.SetGrpcServerOptionsWithLogger(&conf, o.baseConf, o.logger, o.serverInterceptors...)
+	if err != nil {
+		t.Fatalf("grpc server options: %v", err)
+	}
+
+	lis := bufconn.Listen(bufSize)
+
+	s := grpc.NewServer(serverOptions...)
+	register(s)
+
+	go func() {
+		_ = s.Serve(lis)
+	}()
+
+	clientOptions, err := client.SetGrpcClientOptionsWithLogger(&conf, o.baseConf, o.logger, o.clientInterceptors...)
+	if err != nil {
+		s.Stop()
+		t.Fatalf("grpc client options: %v", err)
+	}
+
+	if o.clientConf != nil {
+		credentialsOptions, err := client.ClientOptions(&conf, *o.clientConf)
+		if err != nil {
+			s.Stop()
+			t.Fatalf("grpc client credentials options: %v", err)
+		}
+
+		clientOptions = append(clientOptions, credentialsOptions...)
+	}
+
+	clientOptions = append(clientOptions,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		// the metadata goes after the library interceptors chain
+		grpc.WithChainUnaryInterceptor(metadataUnaryClientInterceptor(o.md)),
+		grpc.WithChainStreamInterceptor(metadataStreamClientInterceptor(o.md)),
+	)
+
+	cc, err := grpc.Dial(target, clientOptions...)
+	if err != nil {
+		s.Stop()
+		t.Fatalf("grpc dial: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = cc.Close()
+		s.Stop()
+	})
+
+	return &Server{Server: s, Conn: cc}
+}
+
+func metadataUnaryClientInterceptor(md metadata.MD) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return invoker(withMetadata(ctx, md), method, req, reply, cc, opts...)
+	}
+}
+
+func metadataStreamClientInterceptor(md metadata.MD) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		return streamer(withMetadata(ctx, md), desc, cc, method, opts...)
+	}
+}
+
+func withMetadata(ctx context.Context, md metadata.MD) context.Context {
+	if len(md) == 0 {
+		return ctx
+	}
+
+	out, _ := metadata.FromOutgoingContext(ctx)
+
+	// the metadata of the call goes first to override the harness one
+	return metadata.NewOutgoingContext(ctx, metadata.Join(out, md))
+}
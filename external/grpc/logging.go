@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+// CodeToLevel returns the log level of the finished call by its status code.
+func CodeToLevel(code codes.Code) zapcore.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.Unauthenticated:
+		return zapcore.InfoLevel
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted, codes.FailedPrecondition,
+		codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// ShouldLog reports whether the finished call must be logged, the failed calls are logged always
+// and the successful ones are sampled unless the method is suppressed.
+func ShouldLog(conf *configs.Logging, fullMethod string, code codes.Code) bool {
+	if conf == nil || !conf.Enabled {
+		return false
+	}
+
+	if code != codes.OK {
+		return true
+	}
+
+	if conf.Suppressed(fullMethod) {
+		return false
+	}
+
+	// nolint:gosec
+	return conf.SampleRate >= 1 || rand.Float64() < conf.SampleRate
+}
+
+func LogCall(logger *zap.SugaredLogger, code codes.Code, msg string, keysAndValues ...interface{}) {
+	switch CodeToLevel(code) {
+	case zapcore.InfoLevel:
+		logger.Infow(msg, keysAndValues...)
+	case zapcore.WarnLevel:
+		logger.Warnw(msg, keysAndValues...)
+	default:
+		logger.Errorw(msg, keysAndValues...)
+	}
+}
+
+// StreamStats counts the messages of the logged stream, the stream can be read and written
+// from different goroutines, so the counters are updated atomically.
+type StreamStats struct {
+	received, sent         int64
+	receivedSize, sentSize int64
+}
+
+func (s *StreamStats) Received(msg interface{}) {
+	atomic.AddInt64(&s.received, 1)
+	atomic.AddInt64(&s.receivedSize, int64(MessageSize(msg)))
+}
+
+func (s *StreamStats) Sent(msg interface{}) {
+	atomic.AddInt64(&s.sent, 1)
+	atomic.AddInt64(&s.sentSize, int64(MessageSize(msg)))
+}
+
+// Fields returns the log fields of the counted messages.
+func (s *StreamStats) Fields() []interface{} {
+	return []interface{}{
+		"grpc.received_messages", atomic.LoadInt64(&s.received),
+		"grpc.received_size", atomic.LoadInt64(&s.receivedSize),
+		"grpc.sent_messages", atomic.LoadInt64(&s.sent),
+		"grpc.sent_size", atomic.LoadInt64(&s.sentSize),
+	}
+}
+
+// MessageSize returns the encoded size of the protobuf message or zero for any other value.
+func MessageSize(msg interface{}) int {
+	if m, ok := msg.(proto.Message); ok {
+		return proto.Size(m)
+	}
+
+	return 0
+}
+
+// MetadataValue returns the first value of the metadata key or an empty string.
+func MetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
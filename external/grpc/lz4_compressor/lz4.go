@@ -0,0 +1,115 @@
+// Package lz4_compressor is a wrapper for using github.com/pierrec/lz4/v4
+// with gRPC.
+package lz4_compressor
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pierrec/lz4/v4"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+const (
+	Name = "lz4"
+
+	MaxLevel = 9
+)
+
+type compressor struct {
+	// current holds *settings, it is replaced by Configure
+	current atomic.Value
+}
+
+type settings struct {
+	level   lz4.CompressionLevel
+	maxSize uint64
+
+	writers sync.Pool
+	readers sync.Pool
+}
+
+func init() {
+	c := &compressor{}
+	c.current.Store(&settings{level: lz4.Fast, maxSize: grpcC.DefaultMaxDecompressedSize})
+
+	encoding.RegisterCompressor(c)
+}
+
+// Configure updates the registered compressor with the level and the decompressed size limit
+// of the compression config, the zero level is the fast compression and 1-9 are the high compression levels.
+func Configure(conf *configs.Compression) error {
+	if conf.Level < 0 || conf.Level > MaxLevel {
+		return fmt.Errorf("invalid lz4 compression level: %d", conf.Level)
+	}
+
+	level := lz4.Fast
+	if conf.Level > 0 {
+		level = lz4.Level1 << (conf.Level - 1)
+	}
+
+	encoding.GetCompressor(Name).(*compressor).current.Store(&settings{level: level, maxSize: grpcC.MaxDecompressedSize(conf)})
+
+	return nil
+}
+
+func (c *compressor) load() *settings {
+	return c.current.Load().(*settings)
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	s := c.load()
+
+	lw, ok := s.writers.Get().(*lz4.Writer)
+	if !ok {
+		lw = lz4.NewWriter(w)
+		if err := lw.Apply(lz4.CompressionLevelOption(s.level)); err != nil {
+			return nil, err
+		}
+	} else {
+		lw.Reset(w)
+	}
+
+	return &lz4WriteCloser{Writer: lw, pool: &s.writers}, nil
+}
+
+type lz4WriteCloser struct {
+	*lz4.Writer
+	pool *sync.Pool
+}
+
+func (z *lz4WriteCloser) Close() error {
+	err := z.Writer.Close()
+
+	// release the destination writer before the lz4 writer is pooled
+	z.Writer.Reset(nil)
+	z.pool.Put(z.Writer)
+
+	return err
+}
+
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	s := c.load()
+
+	lr, ok := s.readers.Get().(*lz4.Reader)
+	if !ok {
+		lr = lz4.NewReader(r)
+	} else {
+		lr.Reset(r)
+	}
+
+	return grpcC.NewLimitedReader(lr, s.maxSize, func() {
+		// release the source reader before the lz4 reader is pooled
+		lr.Reset(nil)
+		s.readers.Put(lr)
+	}), nil
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
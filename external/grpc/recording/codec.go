@@ -0,0 +1,34 @@
+package recording
+
+import (
+	"fmt"
+)
+
+// rawCodec passes the serialized messages as they are, so the records are replayed
+// without the generated message types.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("raw codec: unexpected message type %T", v)
+	}
+
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("raw codec: unexpected message type %T", v)
+	}
+
+	*b = append((*b)[:0], data...)
+
+	return nil
+}
+
+// Name is the proto codec name, so the replayed calls have the same content type as the recorded ones.
+func (rawCodec) Name() string {
+	return "proto"
+}
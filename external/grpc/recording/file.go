@@ -0,0 +1,142 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxRecordSize limits the size of the read records to detect the corrupted files.
+const MaxRecordSize = 64 << 20 // 64Mb
+
+// Writer writes the length-delimited records, it is safe for the concurrent use.
+type Writer struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	out := &Writer{w: bufio.NewWriter(w)}
+	if closer, ok := w.(io.Closer); ok {
+		out.closer = closer
+	}
+
+	return out
+}
+
+// Create creates the records file or truncates the existing one.
+func Create(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriter(f), nil
+}
+
+func (w *Writer) Write(r *Record) error {
+	b, err := proto.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err = w.w.Write(protowire.AppendVarint(nil, uint64(len(b)))); err != nil {
+		return err
+	}
+
+	_, err = w.w.Write(b)
+
+	return err
+}
+
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.w.Flush()
+}
+
+// Close flushes the buffered records and closes the underlying writer.
+func (w *Writer) Close() error {
+	err := w.Flush()
+
+	if w.closer != nil {
+		if closeErr := w.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// Reader reads the length-delimited records.
+type Reader struct {
+	r      *bufio.Reader
+	closer io.Closer
+}
+
+func NewReader(r io.Reader) *Reader {
+	out := &Reader{r: bufio.NewReader(r)}
+	if closer, ok := r.(io.Closer); ok {
+		out.closer = closer
+	}
+
+	return out
+}
+
+// Open opens the records file.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReader(f), nil
+}
+
+// Next returns the next record or io.EOF once all of them are read.
+func (r *Reader) Next() (*Record, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > MaxRecordSize {
+		return nil, fmt.Errorf("record size %d exceeds the limit %d", size, MaxRecordSize)
+	}
+
+	b := make([]byte, size)
+	if _, err = io.ReadFull(r.r, b); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	rec := &Record{}
+	if err = proto.Unmarshal(b, rec); err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+func (r *Reader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package recording
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	writeErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_recording_write_errors_total",
+		Help: "Total number of the calls not recorded by the write errors by method.",
+	}, []string{"method"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(writeErrorsTotal)
+	})
+}
@@ -0,0 +1,53 @@
+// Package recording records the unary calls handled by the server into the file of the length-delimited
+// protobuf records (see record.proto) and replays them against a server to reproduce the traffic.
+package recording
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative record.proto
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// MD returns the recorded metadata of the call.
+func (x *Record) MD() metadata.MD {
+	out := metadata.MD{}
+
+	for _, md := range x.GetMetadata() {
+		out.Append(md.GetKey(), md.GetValues()...)
+	}
+
+	return out
+}
+
+// StartTime returns the start time of the call, it is zero when it isn't recorded.
+func (x *Record) StartTime() time.Time {
+	if x.GetStartUnixNano() == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, x.GetStartUnixNano())
+}
+
+// recordedMetadata returns the metadata to record sorted by the keys, the credentials, the transport
+// and the gRPC reserved keys are dropped.
+func recordedMetadata(md metadata.MD, dropped map[string]bool) []*Metadata {
+	out := make([]*Metadata, 0, len(md))
+
+	for key, values := range md {
+		if dropped[key] || strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-") {
+			continue
+		}
+
+		out = append(out, &Metadata{Key: key, Values: append([]string(nil), values...)})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Key < out[j].Key
+	})
+
+	return out
+}
@@ -0,0 +1,87 @@
+package recording
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+var (
+	// DroppedMetadata are the metadata keys never recorded.
+	DroppedMetadata = []string{grpcC.TokenKey, grpcC.APIKey, "authorization", "content-type", "user-agent"}
+)
+
+// RecordingServerInterceptor records the unary calls of the methods (all of them when empty) by the writer,
+// the methods can be "/package.Service/Method", "package.Service/Method" or "package.Service".
+// The calls are recorded as they are received, so the interceptor goes before the ones changing the requests.
+// The write errors don't fail the calls, they are counted by the grpc_recording_write_errors_total metric.
+func RecordingServerInterceptor(w *Writer, methods ...string) grpc.UnaryServerInterceptor {
+	registerMetrics()
+
+	dropped := make(map[string]bool, len(DroppedMetadata))
+	for _, key := range DroppedMetadata {
+		dropped[key] = true
+	}
+
+	recorded := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		recorded[method] = true
+	}
+
+	matches := func(fullMethod string) bool {
+		if len(recorded) == 0 {
+			return true
+		}
+
+		for _, key := range configs.GrpcMethodKeys(fullMethod) {
+			if recorded[key] {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		in, ok := req.(proto.Message)
+		if !ok || !matches(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		rec := &Record{Method: info.FullMethod, StartUnixNano: start.UnixNano()}
+
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			rec.Metadata = recordedMetadata(md, dropped)
+		}
+
+		// the request is serialized before the handler is able to change it
+		if rec.Request, err = proto.Marshal(in); err != nil {
+			return handler(ctx, req)
+		}
+
+		resp, err = handler(ctx, req)
+		rec.DurationNanos = int64(time.Since(start))
+
+		if err != nil {
+			rec.Status = status.Convert(err).Proto()
+		} else if out, ok := resp.(proto.Message); ok {
+			rec.Response, _ = proto.Marshal(out)
+		}
+
+		// the recording never fails the call
+		if err := w.Write(rec); err != nil {
+			writeErrorsTotal.WithLabelValues(info.FullMethod).Inc()
+		}
+
+		return resp, err
+	}
+}
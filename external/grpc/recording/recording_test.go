@@ -0,0 +1,186 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/grpc/grpctest"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+)
+
+const getMetricsOffsetMethod = "/services.GatewaySaverService/GetMetricsOffset"
+
+type gatewaySaver struct {
+	pb.UnimplementedGatewaySaverServiceServer
+
+	offset  uint64
+	fail    bool
+	cluster []string
+}
+
+func (g *gatewaySaver) GetMetricsOffset(ctx context.Context, _ *pb.ReqGetMetricsOffset) (*pb.ResGetMetricsOffset, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	g.cluster = append(g.cluster, grpcC.MetadataValue(md, grpcC.ClusterIDKey))
+
+	if g.fail {
+		return nil, status.Error(codes.NotFound, "offset not found")
+	}
+
+	return &pb.ResGetMetricsOffset{CurrentOffset: g.offset}, nil
+}
+
+func newGatewaySaver(t *testing.T, srv *gatewaySaver, opts ...grpctest.Option) pb.GatewaySaverServiceClient {
+	s := grpctest.New(t, func(s *grpc.Server) {
+		pb.RegisterGatewaySaverServiceServer(s, srv)
+	}, opts...)
+
+	return pb.NewGatewaySaverServiceClient(s.Conn)
+}
+
+func TestRecordFile(t *testing.T) {
+	start := time.Unix(0, 1640995200000000000)
+
+	rec := &Record{
+		Method:        "/services.GatewaySaverService/GetMetricsOffset",
+		Metadata:      recordedMetadata(metadata.Pairs(grpcC.ClusterIDKey, "bsc-1", grpcC.NameKey, "agent", grpcC.TokenKey, "secret"), nil),
+		Request:       []byte{1, 2},
+		Status:        status.New(codes.NotFound, "offset not found").Proto(),
+		StartUnixNano: start.UnixNano(),
+		DurationNanos: int64(time.Second),
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	require.NoError(t, w.Write(rec))
+	require.NoError(t, w.Write(&Record{Method: "/services.GatewaySaverService/SendMetrics"}))
+	require.NoError(t, w.Flush())
+
+	r := NewReader(&buf)
+
+	got, err := r.Next()
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(rec, got))
+	assert.Equal(t, metadata.Pairs(grpcC.ClusterIDKey, "bsc-1", grpcC.NameKey, "agent", grpcC.TokenKey, "secret"), got.MD())
+	assert.True(t, start.Equal(got.StartTime()))
+
+	got, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "/services.GatewaySaverService/SendMetrics", got.GetMethod())
+	assert.True(t, got.StartTime().IsZero())
+
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk is full")
+}
+
+func TestRecordingWriteErrors(t *testing.T) {
+	w := NewWriter(failingWriter{})
+	// the buffered writer keeps failing after the failed flush
+	require.NoError(t, w.Write(&Record{}))
+	require.Error(t, w.Flush())
+
+	client := newGatewaySaver(t, &gatewaySaver{offset: 1},
+		grpctest.WithServerInterceptors(RecordingServerInterceptor(w)),
+	)
+
+	before := testutil.ToFloat64(writeErrorsTotal.WithLabelValues(getMetricsOffsetMethod))
+
+	// the call isn't failed by the recording
+	_, err := client.GetMetricsOffset(context.Background(), &pb.ReqGetMetricsOffset{})
+	require.NoError(t, err)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(writeErrorsTotal.WithLabelValues(getMetricsOffsetMethod)))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	recorded := &gatewaySaver{offset: 1}
+	client := newGatewaySaver(t, recorded,
+		grpctest.WithServerInterceptors(RecordingServerInterceptor(w, "services.GatewaySaverService")),
+		grpctest.WithClusterID("bsc-1"),
+		grpctest.WithToken("secret"),
+	)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetMetricsOffset(context.Background(), &pb.ReqGetMetricsOffset{})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Flush())
+
+	var cases = []struct {
+		name           string
+		offset         uint64
+		fail           bool
+		opts           []Option
+		wantErrors     int
+		wantMismatches int
+	}{
+		{
+			name:   "same responses",
+			offset: 1,
+			opts:   []Option{WithCompare(), WithOriginalPacing()},
+		},
+		{
+			name:           "different responses",
+			offset:         2,
+			opts:           []Option{WithCompare()},
+			wantMismatches: 3,
+		},
+		{
+			name:           "different statuses",
+			fail:           true,
+			opts:           []Option{WithCompare()},
+			wantErrors:     3,
+			wantMismatches: 3,
+		},
+		{
+			name:       "not compared",
+			fail:       true,
+			wantErrors: 3,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			replayed := &gatewaySaver{offset: c.offset, fail: c.fail}
+			s := grpctest.New(t, func(s *grpc.Server) {
+				pb.RegisterGatewaySaverServiceServer(s, replayed)
+			})
+
+			report, err := Replay(context.Background(), s.Conn, NewReader(bytes.NewReader(buf.Bytes())), c.opts...)
+			require.NoError(t, err)
+
+			assert.Equal(t, 3, report.Calls)
+			assert.Equal(t, c.wantErrors, report.Errors)
+			assert.Len(t, report.Mismatches, c.wantMismatches)
+			assert.Equal(t, []string{"bsc-1", "bsc-1", "bsc-1"}, replayed.cluster)
+		})
+	}
+
+	// the credentials aren't recorded
+	rec, err := NewReader(bytes.NewReader(buf.Bytes())).Next()
+	require.NoError(t, err)
+	assert.Empty(t, rec.MD().Get(grpcC.TokenKey))
+}
@@ -0,0 +1,213 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type Option func(*replayer)
+
+// WithOriginalPacing replays the calls at the pacing of the recording instead of as fast as possible.
+func WithOriginalPacing() Option {
+	return func(r *replayer) {
+		r.pacing = true
+	}
+}
+
+// WithCompare compares the replayed responses and statuses with the recorded ones.
+func WithCompare() Option {
+	return func(r *replayer) {
+		r.compare = true
+	}
+}
+
+// WithMetadata adds the metadata key-value pairs to the replayed calls, e.g. the credentials
+// which are never recorded.
+func WithMetadata(kv ...string) Option {
+	return func(r *replayer) {
+		r.md = metadata.Join(r.md, metadata.Pairs(kv...))
+	}
+}
+
+// WithCallOptions adds the call options to the replayed calls.
+func WithCallOptions(opts ...grpc.CallOption) Option {
+	return func(r *replayer) {
+		r.callOpts = append(r.callOpts, opts...)
+	}
+}
+
+// Mismatch is the replayed call of the status or the response different from the recorded ones.
+type Mismatch struct {
+	Index        int
+	Method       string
+	WantCode     codes.Code
+	GotCode      codes.Code
+	WantResponse []byte
+	GotResponse  []byte
+}
+
+// Report is the result of the replay.
+type Report struct {
+	Calls      int
+	Errors     int
+	Mismatches []Mismatch
+	Duration   time.Duration
+}
+
+type replayer struct {
+	pacing   bool
+	compare  bool
+	md       metadata.MD
+	callOpts []grpc.CallOption
+}
+
+// Replay calls the connection by the records of the reader sequentially, the calls failed with any status
+// are counted by the report errors and the different results are reported as mismatches when compared.
+func Replay(ctx context.Context, conn grpc.ClientConnInterface, r *Reader, opts ...Option) (*Report, error) {
+	rp := &replayer{}
+	for _, op := range opts {
+		op(rp)
+	}
+
+	callOpts := append([]grpc.CallOption{grpc.ForceCodec(rawCodec{})}, rp.callOpts...)
+
+	var (
+		report = &Report{}
+		begin  = time.Now()
+		first  time.Time
+	)
+
+	for ; ; report.Calls++ {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return report, err
+		}
+
+		if rp.pacing && !rec.StartTime().IsZero() {
+			if first.IsZero() {
+				first = rec.StartTime()
+			}
+
+			if err = sleep(ctx, time.Until(begin.Add(rec.StartTime().Sub(first)))); err != nil {
+				return report, err
+			}
+		}
+
+		req, resp := rec.Request, []byte(nil)
+		callCtx := metadata.NewOutgoingContext(ctx, metadata.Join(rec.MD(), rp.md))
+
+		err = conn.Invoke(callCtx, rec.Method, &req, &resp, callOpts...)
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+
+		if err != nil {
+			report.Errors++
+		}
+
+		if rp.compare {
+			if m, ok := compareRecord(report.Calls, rec, status.Code(err), resp); !ok {
+				report.Mismatches = append(report.Mismatches, m)
+			}
+		}
+	}
+
+	report.Duration = time.Since(begin)
+
+	return report, nil
+}
+
+func compareRecord(idx int, rec *Record, code codes.Code, resp []byte) (Mismatch, bool) {
+	m := Mismatch{
+		Index:        idx,
+		Method:       rec.Method,
+		WantCode:     codes.OK,
+		GotCode:      code,
+		WantResponse: rec.Response,
+		GotResponse:  resp,
+	}
+
+	if rec.Status != nil {
+		m.WantCode = codes.Code(rec.Status.GetCode())
+	}
+
+	if m.WantCode != m.GotCode {
+		return m, false
+	}
+
+	return m, code != codes.OK || equalResponses(rec.Method, rec.Response, resp)
+}
+
+// equalResponses compares the responses as the messages of the method output type when it is registered,
+// so the different serializations of the same message are equal, otherwise the bytes are compared.
+func equalResponses(fullMethod string, want, got []byte) bool {
+	if bytes.Equal(want, got) {
+		return true
+	}
+
+	service, method := splitMethod(fullMethod)
+
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return false
+	}
+
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return false
+	}
+
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return false
+	}
+
+	wantMsg, gotMsg := dynamicpb.NewMessage(md.Output()), dynamicpb.NewMessage(md.Output())
+	if proto.Unmarshal(want, wantMsg) != nil || proto.Unmarshal(got, gotMsg) != nil {
+		return false
+	}
+
+	return proto.Equal(wantMsg, gotMsg)
+}
+
+func splitMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+
+	return fullMethod, ""
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
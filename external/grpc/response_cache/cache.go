@@ -0,0 +1,152 @@
+// Package response_cache caches the responses of the read-only unary gRPC calls in a cache.Cache.
+package response_cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/dysnix/predictkube-libs/external/cache"
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+const (
+	keyPrefix = "response_cache"
+
+	ServerSide = "server"
+	ClientSide = "client"
+)
+
+// entry is the stored response, the type is needed by the server side to create the message.
+type entry struct {
+	Type string `json:"type"`
+	Data []byte `json:"data"`
+}
+
+// Cache stores the responses by the method, the cluster id and the deterministic encoding of the request.
+// The entries of the cluster method are invalidated by bumping their version, the stale ones expire by the TTL.
+type Cache struct {
+	conf  *configs.ResponseCache
+	cache cache.Cache
+	side  string
+}
+
+// New returns the response cache of the side label used by the metrics.
+func New(conf *configs.ResponseCache, c cache.Cache, side string) *Cache {
+	registerMetrics()
+
+	return &Cache{conf: conf, cache: c, side: side}
+}
+
+// Get returns the cached response of the request, the response is nil on the cache miss.
+// The new message is created when the reply is nil.
+func (c *Cache) Get(ctx context.Context, fullMethod, clusterID string, req, reply proto.Message) (proto.Message, error) {
+	key, ok, err := c.key(ctx, fullMethod, clusterID, req)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var stored entry
+	if err = c.cache.Get(ctx, key, &stored); err != nil {
+		requestsTotal.WithLabelValues(fullMethod, c.side, "miss").Inc()
+
+		if errors.Is(err, cache.ErrNil) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if reply == nil {
+		mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(stored.Type))
+		if err != nil {
+			return nil, err
+		}
+
+		reply = mt.New().Interface()
+	} else {
+		proto.Reset(reply)
+	}
+
+	if err = proto.Unmarshal(stored.Data, reply); err != nil {
+		return nil, err
+	}
+
+	requestsTotal.WithLabelValues(fullMethod, c.side, "hit").Inc()
+
+	return reply, nil
+}
+
+// Set stores the response of the request with the method TTL, the responses of the not cached methods are skipped.
+func (c *Cache) Set(ctx context.Context, fullMethod, clusterID string, req, resp proto.Message) error {
+	key, ok, err := c.key(ctx, fullMethod, clusterID, req)
+	if err != nil || !ok {
+		return err
+	}
+
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	_, ttl := c.conf.MethodFor(fullMethod)
+
+	return c.cache.Set(ctx, entry{Type: string(resp.ProtoReflect().Descriptor().FullName()), Data: data}, key, ttl)
+}
+
+// Invalidate drops the cached responses of the cluster for the Methods keys of the config.
+func (c *Cache) Invalidate(ctx context.Context, clusterID string, methods ...string) error {
+	for _, method := range methods {
+		// the version outlives the entries stored before the invalidation
+		ttl, ok := c.conf.Methods[method]
+		if !ok {
+			continue
+		}
+
+		if err := c.cache.Set(ctx, time.Now().UnixNano(), versionKey(clusterID, method), ttl); err != nil {
+			return err
+		}
+
+		invalidationsTotal.WithLabelValues(method, c.side).Inc()
+	}
+
+	return nil
+}
+
+// InvalidateAfter drops the cached responses invalidated by the successful call of the mutating method.
+func (c *Cache) InvalidateAfter(ctx context.Context, fullMethod, clusterID string) error {
+	return c.Invalidate(ctx, clusterID, c.conf.InvalidatedFor(fullMethod)...)
+}
+
+func (c *Cache) key(ctx context.Context, fullMethod, clusterID string, req proto.Message) (_ string, ok bool, err error) {
+	method, ttl := c.conf.MethodFor(fullMethod)
+	if ttl <= 0 {
+		return "", false, nil
+	}
+
+	var version int64
+	if err = c.cache.Get(ctx, versionKey(clusterID, method), &version); err != nil && !errors.Is(err, cache.ErrNil) {
+		return "", false, err
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", false, err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return fmt.Sprintf("%s:%s:%s:%s:%s", keyPrefix, clusterID, fullMethod, strconv.FormatInt(version, 36), hex.EncodeToString(sum[:])), true, nil
+}
+
+func versionKey(clusterID, method string) string {
+	return fmt.Sprintf("%s:version:%s:%s", keyPrefix, clusterID, method)
+}
@@ -0,0 +1,30 @@
+package response_cache
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_response_cache_requests_total",
+		Help: "Total number of response cache lookups by method, side and result (hit or miss).",
+	}, []string{"grpc_method", "side", "result"})
+
+	invalidationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_response_cache_invalidations_total",
+		Help: "Total number of response cache invalidations by the cached method key and side.",
+	}, []string{"method", "side"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(
+			requestsTotal,
+			invalidationsTotal,
+		)
+	})
+}
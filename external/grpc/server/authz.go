@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+type principalKey struct{}
+
+// Principal is the authenticated identity of the call, the PrincipalExtractor or the custom authentication
+// interceptors put it into the call context by ContextWithPrincipal before the authorization.
+type Principal struct {
+	Subject string
+	Roles   []string
+	Scopes  []string
+	Claims  map[string]string
+}
+
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// Authorizer allows the calls by the rules of the access control policy and denies all the other ones.
+type Authorizer struct {
+	policy configs.AuthzPolicy
+	logger *zap.SugaredLogger
+}
+
+// NewAuthorizer returns the authorizer of the policy, the decisions are logged by the logger.
+func NewAuthorizer(policy *configs.AuthzPolicy, logger *zap.SugaredLogger) (*Authorizer, error) {
+	if policy == nil {
+		return nil, fmt.Errorf("authorization policy is not set")
+	}
+
+	for i, rule := range policy.Rules {
+		if len(rule.Methods) == 0 {
+			return nil, fmt.Errorf("authorization rule %d %q has no methods", i, rule.Name)
+		}
+	}
+
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+
+	return &Authorizer{policy: *policy, logger: logger}, nil
+}
+
+// newAuthz returns the principal extractor and the authorizer of the policy file of the configs.
+func newAuthz(conf *configs.Authz, logger *zap.SugaredLogger) (*PrincipalExtractor, *Authorizer, error) {
+	policy := &configs.AuthzPolicy{}
+	if err := configs.ReadConfigFile(conf.PolicyPath, policy); err != nil {
+		return nil, nil, fmt.Errorf("authorization policy: %w", err)
+	}
+
+	authorizer, err := NewAuthorizer(policy, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	extractor, err := NewPrincipalExtractor(conf, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return extractor, authorizer, nil
+}
+
+// Authorize returns the PermissionDenied error when none of the rules allows the call of the context principal,
+// the denials of the dry-run policy are only logged.
+func (a *Authorizer) Authorize(ctx context.Context, fullMethod string) error {
+	p, _ := PrincipalFromContext(ctx)
+
+	for _, rule := range a.policy.Rules {
+		if rule.MatchesMethod(fullMethod) && allows(&rule, p) {
+			a.logger.Debugw("authorization allowed", "grpc.method", fullMethod, "subject", subjectOf(p), "rule", rule.Name)
+			return nil
+		}
+	}
+
+	if a.policy.DryRun {
+		a.logger.Infow("authorization would deny (dry run)", "grpc.method", fullMethod, "subject", subjectOf(p))
+		return nil
+	}
+
+	a.logger.Warnw("authorization denied", "grpc.method", fullMethod, "subject", subjectOf(p))
+
+	return status.Errorf(codes.PermissionDenied, "method %s is not allowed", fullMethod)
+}
+
+func (a *Authorizer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if err = a.Authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (a *Authorizer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.Authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// allows reports whether the principal satisfies all the conditions of the rule,
+// the calls without the principal are allowed only by the rules without any conditions.
+func allows(rule *configs.AuthzRule, p *Principal) bool {
+	if len(rule.Roles) == 0 && len(rule.Scopes) == 0 && len(rule.Claims) == 0 {
+		return true
+	}
+
+	if p == nil {
+		return false
+	}
+
+	if len(rule.Roles) > 0 && !intersects(rule.Roles, p.Roles) {
+		return false
+	}
+
+	if len(rule.Scopes) > 0 && !intersects(rule.Scopes, p.Scopes) {
+		return false
+	}
+
+	for claim, value := range rule.Claims {
+		if got, ok := p.Claims[claim]; !ok || got != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func subjectOf(p *Principal) string {
+	if p == nil {
+		return ""
+	}
+
+	return p.Subject
+}
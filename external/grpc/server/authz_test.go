@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+const testPolicy = `
+dryRun: false
+rules:
+  - name: health
+    methods: ["grpc.health.v1.Health"]
+  - name: agents
+    roles: ["agent"]
+    methods: ["services.GatewaySaverService/SendMetrics"]
+  - name: public-api
+    scopes: ["metrics:read"]
+    claims:
+      tier: pro
+    methods: ["services.GatewaySaverService/GetMetricsOffset"]
+  - name: admins
+    roles: ["admin"]
+    methods: ["*"]
+`
+
+func readTestPolicy(t *testing.T) *configs.AuthzPolicy {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0600))
+
+	policy := &configs.AuthzPolicy{}
+	require.NoError(t, configs.ReadConfigFile(path, policy))
+
+	return policy
+}
+
+func TestAuthorizer(t *testing.T) {
+	a, err := NewAuthorizer(readTestPolicy(t), nil)
+	require.NoError(t, err)
+
+	var cases = []struct {
+		name      string
+		method    string
+		principal *Principal
+		want      codes.Code
+	}{
+		{
+			name:   "rule without conditions",
+			method: "/grpc.health.v1.Health/Check",
+			want:   codes.OK,
+		},
+		{
+			name:   "no principal",
+			method: "/services.GatewaySaverService/SendMetrics",
+			want:   codes.PermissionDenied,
+		},
+		{
+			name:      "role",
+			method:    "/services.GatewaySaverService/SendMetrics",
+			principal: &Principal{Subject: "agent-1", Roles: []string{"agent"}},
+			want:      codes.OK,
+		},
+		{
+			name:      "role of other method",
+			method:    "/services.GatewaySaverService/GetMetricsOffset",
+			principal: &Principal{Subject: "agent-1", Roles: []string{"agent"}},
+			want:      codes.PermissionDenied,
+		},
+		{
+			name:      "scope and claims",
+			method:    "/services.GatewaySaverService/GetMetricsOffset",
+			principal: &Principal{Scopes: []string{"metrics:read"}, Claims: map[string]string{"tier": "pro"}},
+			want:      codes.OK,
+		},
+		{
+			name:      "scope without claims",
+			method:    "/services.GatewaySaverService/GetMetricsOffset",
+			principal: &Principal{Scopes: []string{"metrics:read"}, Claims: map[string]string{"tier": "free"}},
+			want:      codes.PermissionDenied,
+		},
+		{
+			name:      "all methods",
+			method:    "/services.Missing/Method",
+			principal: &Principal{Roles: []string{"admin"}},
+			want:      codes.OK,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.principal != nil {
+				ctx = ContextWithPrincipal(ctx, c.principal)
+			}
+
+			assert.Equal(t, c.want, status.Code(a.Authorize(ctx, c.method)))
+		})
+	}
+}
+
+func TestAuthorizerDryRun(t *testing.T) {
+	policy := readTestPolicy(t)
+	policy.DryRun = true
+
+	core, logs := observer.New(zap.InfoLevel)
+
+	a, err := NewAuthorizer(policy, zap.New(core).Sugar())
+	require.NoError(t, err)
+
+	assert.NoError(t, a.Authorize(context.Background(), "/services.GatewaySaverService/SendMetrics"))
+	assert.Equal(t, 1, logs.FilterMessageSnippet("would deny").Len())
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	_, err := NewAuthorizer(nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewAuthorizer(&configs.AuthzPolicy{Rules: []configs.AuthzRule{{Name: "empty"}}}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/grpc/compressors"
+)
+
+// the grpc transport stream knows the encoding of the received messages
+type recvCompressor interface {
+	RecvCompress() string
+}
+
+// CompressionUnaryServerInterceptor rejects the requests compressed with the encodings not accepted by the config.
+// The responses are compressed with the encoding of the request by grpc itself.
+func CompressionUnaryServerInterceptor(conf *configs.Compression) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if err = checkEncoding(ctx, conf); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func CompressionStreamServerInterceptor(conf *configs.Compression) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkEncoding(ss.Context(), conf); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func checkEncoding(ctx context.Context, conf *configs.Compression) error {
+	stream, ok := grpc.ServerTransportStreamFromContext(ctx).(recvCompressor)
+	if !ok {
+		return nil
+	}
+
+	encoding := stream.RecvCompress()
+
+	t, err := compressors.TypeOf(encoding)
+	if err != nil || !conf.Accepts(t) {
+		return status.Errorf(codes.Unimplemented, "grpc: request encoding %q is not accepted", encoding)
+	}
+
+	return nil
+}
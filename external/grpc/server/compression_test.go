@@ -0,0 +1,72 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+	"github.com/dysnix/predictkube-libs/external/grpc/grpctest"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+)
+
+func TestCompressionServerInterceptors(t *testing.T) {
+	var cases = []struct {
+		name     string
+		conf     configs.Compression
+		wantCode codes.Code
+	}{
+		{
+			name:     "all encodings are accepted by default",
+			conf:     configs.Compression{Enabled: true, Type: enums.Gzip},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "accepted encoding",
+			conf:     configs.Compression{Enabled: true, Type: enums.Zstd, AcceptedEncodings: []enums.CompressionType{enums.Zstd}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "encoding isn't accepted",
+			conf:     configs.Compression{Enabled: true, Type: enums.Gzip, AcceptedEncodings: []enums.CompressionType{enums.Zstd}},
+			wantCode: codes.Unimplemented,
+		},
+		{
+			name: "rejected encoding takes precedence",
+			conf: configs.Compression{
+				Enabled:           true,
+				Type:              enums.Snappy,
+				AcceptedEncodings: []enums.CompressionType{enums.Snappy},
+				RejectedEncodings: []enums.CompressionType{enums.Snappy},
+			},
+			wantCode: codes.Unimplemented,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := grpctest.New(t, func(s *grpc.Server) {
+				pb.RegisterGatewaySaverServiceServer(s, &gatewaySaver{})
+				healthpb.RegisterHealthServer(s, health.NewServer())
+			}, grpctest.WithConfigs(&configs.GRPC{Compression: c.conf}, &configs.Base{}))
+
+			_, err := pb.NewGatewaySaverServiceClient(s.Conn).GetMetricsOffset(context.Background(), &pb.ReqGetMetricsOffset{})
+			assert.Equal(t, c.wantCode, status.Code(err))
+
+			// the health stream goes through the stream interceptor
+			stream, err := healthpb.NewHealthClient(s.Conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+
+			assert.Equal(t, c.wantCode, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+// DeadlineServerInterceptor bounds the unary handler duration by the configured method timeout,
+// the handler context gets the deadline and the handler errors caused by it are DEADLINE_EXCEEDED.
+// The handler runs in the call goroutine, so handlers which ignore the context delay the response
+// but never outlive the call and its concurrency limiter slot.
+func DeadlineServerInterceptor(conf *configs.Deadlines) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		timeout := conf.HandlerTimeoutFor(info.FullMethod)
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, deadlineError(ctx, info.FullMethod, err)
+		}
+
+		return resp, nil
+	}
+}
+
+// DeadlineStreamServerInterceptor is the stream counterpart of DeadlineServerInterceptor,
+// the timeout covers the whole stream lifetime.
+func DeadlineStreamServerInterceptor(conf *configs.Deadlines) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		timeout := conf.HandlerTimeoutFor(info.FullMethod)
+		if timeout <= 0 {
+			return handler(srv, ss)
+		}
+
+		ctx, cancel := context.WithTimeout(ss.Context(), timeout)
+		defer cancel()
+
+		if err := handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx}); err != nil {
+			return deadlineError(ctx, info.FullMethod, err)
+		}
+
+		return nil
+	}
+}
+
+// deadlineError converts the handler error returned after the end of the handler context to its status,
+// the status errors of the handler are kept as is.
+func deadlineError(ctx context.Context, fullMethod string, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return status.Errorf(codes.DeadlineExceeded, "%s handler deadline exceeded", fullMethod)
+	}
+
+	return status.Error(codes.Canceled, ctx.Err().Error())
+}
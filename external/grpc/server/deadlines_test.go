@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestHandlerTimeoutFor(t *testing.T) {
+	conf := &configs.Deadlines{
+		Default:    time.Second,
+		Methods:    map[string]time.Duration{"services.GatewaySaverService": time.Minute},
+		MaxHandler: 10 * time.Second,
+		Handlers: map[string]time.Duration{
+			"services.GatewaySaverService/SendMetrics":   2 * time.Second,
+			"services.GatewaySaverService/StreamMetrics": time.Hour,
+			"/services.GatewaySaverService/ListClusters": time.Millisecond,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		conf     *configs.Deadlines
+		method   string
+		expected time.Duration
+	}{
+		{"nil config", nil, "/services.GatewaySaverService/SendMetrics", 0},
+		{"handler override", conf, "/services.GatewaySaverService/SendMetrics", 2 * time.Second},
+		{"capped by max handler", conf, "/services.GatewaySaverService/StreamMetrics", 10 * time.Second},
+		{"full method key", conf, "/services.GatewaySaverService/ListClusters", time.Millisecond},
+		{"client methods are ignored", conf, "/services.GatewaySaverService/GetMetricsOffset", 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.conf.HandlerTimeoutFor(tt.method))
+		})
+	}
+}
+
+func TestDeadlineServerInterceptor(t *testing.T) {
+	conf := &configs.Deadlines{
+		Handlers: map[string]time.Duration{"services.GatewaySaverService/SendMetrics": 20 * time.Millisecond},
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		sleep    time.Duration
+		expected codes.Code
+	}{
+		{"finished in time", "/services.GatewaySaverService/SendMetrics", 0, codes.OK},
+		{"handler deadline exceeded", "/services.GatewaySaverService/SendMetrics", time.Second, codes.DeadlineExceeded},
+		{"no limit", "/services.GatewaySaverService/GetMetricsOffset", 50 * time.Millisecond, codes.OK},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sleep := func(ctx context.Context) error {
+				select {
+				case <-time.After(tt.sleep):
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			_, err := DeadlineServerInterceptor(conf)(context.Background(), nil,
+				&grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, _ interface{}) (interface{}, error) {
+					return "ok", sleep(ctx)
+				})
+			assert.Equal(t, tt.expected, status.Code(err))
+
+			err = DeadlineStreamServerInterceptor(conf)(nil, &testServerStream{ctx: context.Background()},
+				&grpc.StreamServerInfo{FullMethod: tt.method},
+				func(_ interface{}, ss grpc.ServerStream) error {
+					return sleep(ss.Context())
+				})
+			assert.Equal(t, tt.expected, status.Code(err))
+		})
+	}
+}
+
+func TestDeadlineServerInterceptorPanic(t *testing.T) {
+	conf := &configs.Deadlines{MaxHandler: time.Second}
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = DeadlineStreamServerInterceptor(conf)(nil, &testServerStream{ctx: context.Background()},
+			&grpc.StreamServerInfo{FullMethod: "/services.GatewaySaverService/SendMetrics"},
+			func(interface{}, grpc.ServerStream) error {
+				panic("boom")
+			})
+	})
+}
+
+func TestDeadlineServerInterceptorWaitsForHandler(t *testing.T) {
+	conf := &configs.Deadlines{MaxHandler: 10 * time.Millisecond}
+	info := &grpc.UnaryServerInfo{FullMethod: "/services.GatewaySaverService/SendMetrics"}
+
+	var finished bool
+
+	// the handler ignores the context, so the interceptor returns only when it's finished
+	_, err := DeadlineServerInterceptor(conf)(context.Background(), nil, info,
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			time.Sleep(50 * time.Millisecond)
+			finished = true
+
+			return nil, ctx.Err()
+		})
+	assert.True(t, finished)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	err = DeadlineStreamServerInterceptor(conf)(nil, &testServerStream{ctx: context.Background()},
+		&grpc.StreamServerInfo{FullMethod: info.FullMethod},
+		func(_ interface{}, ss grpc.ServerStream) error {
+			<-ss.Context().Done()
+			return status.Error(codes.Aborted, "aborted by the handler")
+		})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+}
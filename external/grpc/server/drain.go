@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	pb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	healthServicePrefix = "/" + pb.Health_ServiceDesc.ServiceName + "/"
+)
+
+// Drainer rejects the new calls with the Unavailable code once it is draining,
+// the health checks are still served so the clients are able to see the not serving status.
+type Drainer struct {
+	draining int32
+}
+
+func (d *Drainer) Drain() {
+	atomic.StoreInt32(&d.draining, 1)
+}
+
+func (d *Drainer) Draining() bool {
+	return atomic.LoadInt32(&d.draining) == 1
+}
+
+func (d *Drainer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if err = d.check(info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (d *Drainer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := d.check(info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func (d *Drainer) check(fullMethod string) error {
+	if d.Draining() && !strings.HasPrefix(fullMethod, healthServicePrefix) {
+		return status.Error(codes.Unavailable, "server is draining")
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/dysnix/predictkube-libs/external/app_errors"
+)
+
+// ErrorServerInterceptor converts the errors of the handlers to the gRPC status with the details
+// of the application errors, the cache and gorm errors get the codes of their kinds.
+func ErrorServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if resp, err = handler(ctx, req); err != nil {
+			return resp, app_errors.ToStatus(err).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+func ErrorStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return app_errors.ToStatus(err).Err()
+		}
+
+		return nil
+	}
+}
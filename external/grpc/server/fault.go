@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/fault"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+// FaultServerInterceptor injects the faults of the injector into the unary calls, the dropped calls are handled
+// but their responses are never sent and the calls wait for the client to give up.
+func FaultServerInterceptor(i *fault.Injector) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		f := i.Pick(fault.ServerSide, info.FullMethod, incomingClusterID(ctx))
+		if f == nil {
+			return handler(ctx, req)
+		}
+
+		if err = f.Delay(ctx); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+
+		if err = f.Err(); err != nil {
+			return nil, err
+		}
+
+		if resp, err = handler(ctx, req); err != nil || !f.Drop {
+			return resp, err
+		}
+
+		<-ctx.Done()
+
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// FaultStreamServerInterceptor injects the faults of the injector into the streams,
+// the messages sent by the dropped streams are discarded.
+func FaultStreamServerInterceptor(i *fault.Injector) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		f := i.Pick(fault.ServerSide, info.FullMethod, incomingClusterID(ss.Context()))
+		if f == nil {
+			return handler(srv, ss)
+		}
+
+		if err := f.Delay(ss.Context()); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		if err := f.Err(); err != nil {
+			return err
+		}
+
+		if !f.Drop {
+			return handler(srv, ss)
+		}
+
+		if err := handler(srv, &droppedServerStream{ServerStream: ss}); err != nil {
+			return err
+		}
+
+		<-ss.Context().Done()
+
+		return status.FromContextError(ss.Context().Err()).Err()
+	}
+}
+
+type droppedServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *droppedServerStream) SendMsg(_ interface{}) error {
+	return nil
+}
+
+func incomingClusterID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return grpcC.MetadataValue(md, grpcC.ClusterIDKey)
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+)
+
+const (
+	headerField = "header"
+)
+
+// HeaderSetter can be implemented by the hand-written request wrappers to skip the reflection,
+// the generated messages don't implement it and their header field is set with protoreflect.
+type HeaderSetter interface {
+	GetHeader() *pb.Header
+	SetHeader(*pb.Header)
+}
+
+// InjectClientHeader merges the client identity of the metadata into the header of the message,
+// the messages without the header are left as is.
+func InjectClientHeader(msg interface{}, md metadata.MD) {
+	switch m := msg.(type) {
+	case HeaderSetter:
+		m.SetHeader(MergeClientHeader(m.GetHeader(), md))
+	case proto.Message:
+		injectHeaderField(m.ProtoReflect(), md)
+	}
+}
+
+// MergeClientHeader sets the fields of the header carried by the metadata and keeps the others,
+// the cluster id of the metadata overrides the one sent by the client as it comes from the auth.
+// The header has no client name field, the handlers read it from the grpcC.NameKey metadata.
+func MergeClientHeader(header *pb.Header, md metadata.MD) *pb.Header {
+	if header == nil {
+		header = &pb.Header{}
+	}
+
+	if clusterID := grpcC.MetadataValue(md, grpcC.ClusterIDKey); len(clusterID) > 0 {
+		header.ClusterId = clusterID
+	}
+
+	return header
+}
+
+func injectHeaderField(msg protoreflect.Message, md metadata.MD) {
+	fd := msg.Descriptor().Fields().ByName(headerField)
+	if fd == nil || fd.IsList() || fd.IsMap() || fd.Message() == nil ||
+		fd.Message().FullName() != (&pb.Header{}).ProtoReflect().Descriptor().FullName() {
+		return
+	}
+
+	var header *pb.Header
+	if msg.Has(fd) {
+		header, _ = msg.Get(fd).Message().Interface().(*pb.Header)
+	}
+
+	msg.Set(fd, protoreflect.ValueOfMessage(MergeClientHeader(header, md).ProtoReflect()))
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+)
+
+type headerSetterReq struct {
+	header *pb.Header
+}
+
+func (r *headerSetterReq) GetHeader() *pb.Header {
+	return r.header
+}
+
+func (r *headerSetterReq) SetHeader(header *pb.Header) {
+	r.header = header
+}
+
+func TestInjectClientHeader(t *testing.T) {
+	md := metadata.Pairs(grpcC.ClusterIDKey, "bsc-1")
+
+	setter := &headerSetterReq{}
+	InjectClientHeader(setter, md)
+	assert.Equal(t, "bsc-1", setter.GetHeader().GetClusterId())
+
+	// the messages without the header field are left as is
+	header := &pb.Header{ClusterId: "eth-1"}
+	InjectClientHeader(header, md)
+	assert.Equal(t, "eth-1", header.GetClusterId())
+}
+
+type headerRecvStream struct {
+	testServerStream
+	received int
+}
+
+func (s *headerRecvStream) RecvMsg(m interface{}) error {
+	s.received++
+	m.(*pb.ReqSendMetrics).Header = &pb.Header{ClusterId: "eth-1"}
+
+	return nil
+}
+
+func TestInjectClientMetadataStreamInterceptor(t *testing.T) {
+	var cases = []struct {
+		name          string
+		ctx           context.Context
+		wantClusterID string
+	}{
+		{
+			name:          "metadata overrides the cluster id of every message",
+			ctx:           metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcC.ClusterIDKey, "bsc-1")),
+			wantClusterID: "bsc-1",
+		},
+		{
+			name:          "stream without metadata",
+			ctx:           context.Background(),
+			wantClusterID: "eth-1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ss := &headerRecvStream{testServerStream: testServerStream{ctx: c.ctx}}
+
+			err := InjectClientMetadataStreamInterceptor()(nil, ss, &grpc.StreamServerInfo{},
+				func(_ interface{}, stream grpc.ServerStream) error {
+					for i := 0; i < 2; i++ {
+						req := &pb.ReqSendMetrics{}
+						if err := stream.RecvMsg(req); err != nil {
+							return err
+						}
+
+						assert.Equal(t, c.wantClusterID, req.GetHeader().GetClusterId())
+					}
+
+					return nil
+				})
+			require.NoError(t, err)
+			assert.Equal(t, 2, ss.received)
+		})
+	}
+}
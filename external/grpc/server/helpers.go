@@ -7,14 +7,19 @@ import (
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/status"
 
 	"github.com/dysnix/predictkube-libs/external/configs"
-	_ "github.com/dysnix/predictkube-libs/external/grpc/zstd_compressor"
+	"github.com/dysnix/predictkube-libs/external/enums"
+	"github.com/dysnix/predictkube-libs/external/fault"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/grpc/compressors"
+	"github.com/dysnix/predictkube-libs/external/limiter"
 )
 
 const (
@@ -36,10 +41,28 @@ func CheckNetErrClosing(err error) error {
 	return nil
 }
 
+// SetGrpcServerOptions returns the server options of the configs with the interceptors chain of the library,
+// the calls logging is discarded, use SetGrpcServerOptionsWithLogger to keep it.
 func SetGrpcServerOptions(conf *configs.GRPC, baseConf *configs.Base, internalInterceptors ...grpc.UnaryServerInterceptor) (options []grpc.ServerOption, err error) {
+	return setGrpcServerOptions(conf, baseConf, nil, nil, internalInterceptors...)
+}
+
+// SetGrpcServerOptionsWithLogger is SetGrpcServerOptions with the logger of the calls logging.
+func SetGrpcServerOptionsWithLogger(conf *configs.GRPC, baseConf *configs.Base, logger *zap.SugaredLogger, internalInterceptors ...grpc.UnaryServerInterceptor) (options []grpc.ServerOption, err error) {
+	return setGrpcServerOptions(conf, baseConf, logger, nil, internalInterceptors...)
+}
+
+// setGrpcServerOptions puts the drainer at the head of the chain, so the rejected calls don't pass through
+// the limiter, the caches and the other interceptors.
+func setGrpcServerOptions(conf *configs.GRPC, baseConf *configs.Base, logger *zap.SugaredLogger, drainer *Drainer, internalInterceptors ...grpc.UnaryServerInterceptor) (options []grpc.ServerOption, err error) {
 	unaryInterceptors := make([]grpc.UnaryServerInterceptor, 0)
 	streamInterceptors := make([]grpc.StreamServerInterceptor, 0)
 
+	if drainer != nil {
+		unaryInterceptors = append(unaryInterceptors, drainer.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, drainer.StreamServerInterceptor())
+	}
+
 	if conf.Conn.Timeout > 0 {
 		options = append(options, grpc.ConnectionTimeout(conf.Conn.Timeout))
 	}
@@ -47,8 +70,11 @@ func SetGrpcServerOptions(conf *configs.GRPC, baseConf *configs.Base, internalIn
 	if conf.Keepalive != nil {
 		options = append(options, grpc.KeepaliveParams(
 			keepalive.ServerParameters{
-				Time:    conf.Keepalive.Time,
-				Timeout: conf.Keepalive.Timeout,
+				Time:                  conf.Keepalive.Time,
+				Timeout:               conf.Keepalive.Timeout,
+				MaxConnectionIdle:     conf.Keepalive.MaxConnectionIdle,
+				MaxConnectionAge:      conf.Keepalive.MaxConnectionAge,
+				MaxConnectionAgeGrace: conf.Keepalive.MaxConnectionAgeGrace,
 			},
 		))
 
@@ -62,6 +88,20 @@ func SetGrpcServerOptions(conf *configs.GRPC, baseConf *configs.Base, internalIn
 		}
 	}
 
+	if conf.TLS != nil && !conf.Conn.Insecure {
+		tlsConf, err := grpcC.NewServerTLSConfig(conf.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConf)))
+	}
+
+	// all the compressors are registered to accept any of them, only the configured ones are tuned
+	if err = compressors.Configure(&conf.Compression); err != nil {
+		return nil, err
+	}
+
 	if conf.Conn.ReadBufferSize > 0 {
 		options = append(options, grpc.ReadBufferSize(int(conf.Conn.ReadBufferSize)))
 	}
@@ -81,17 +121,81 @@ func SetGrpcServerOptions(conf *configs.GRPC, baseConf *configs.Base, internalIn
 	unaryInterceptors = append(unaryInterceptors,
 		PanicServerInterceptor(func(ctx context.Context, err error, params ...interface{}) error {
 			//TODO:? can be any other logic...
-			return status.Errorf(codes.Unknown, "panic triggered: %v", err)
+			return status.Errorf(codes.Internal, "panic triggered: %v", err)
 		}),
 	)
 
 	// TODO: implement all needed interceptors...
 
+	if baseConf.Tracing.Enabled {
+		unaryInterceptors = append(unaryInterceptors, TracingUnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, TracingStreamServerInterceptor())
+	}
+
 	if baseConf.Monitoring.Enabled {
 		unaryInterceptors = append(unaryInterceptors, grpc_prometheus.UnaryServerInterceptor)
 		streamInterceptors = append(streamInterceptors, grpc_prometheus.StreamServerInterceptor)
 	}
 
+	if conf.Logging != nil && conf.Logging.Enabled {
+		if logger == nil {
+			logger = zap.NewNop().Sugar()
+		}
+
+		unaryInterceptors = append(unaryInterceptors, LoggingUnaryServerInterceptor(logger, conf.Logging))
+		streamInterceptors = append(streamInterceptors, LoggingStreamServerInterceptor(logger, conf.Logging))
+	}
+
+	if conf.TenantMetrics != nil && conf.TenantMetrics.Enabled {
+		metrics := DefaultTenantMetrics(conf.TenantMetrics)
+		unaryInterceptors = append(unaryInterceptors, metrics.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, metrics.StreamServerInterceptor())
+	}
+
+	// the denied calls are observed by the metrics and the logs, but don't take the slots of the limiter
+	if conf.Authz != nil && conf.Authz.Enabled {
+		extractor, authorizer, err := newAuthz(conf.Authz, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		unaryInterceptors = append(unaryInterceptors, extractor.UnaryServerInterceptor(), authorizer.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, extractor.StreamServerInterceptor(), authorizer.StreamServerInterceptor())
+	}
+
+	// the calls over the limit are rejected before any work is done for them
+	if conf.ConcurrencyLimit != nil && conf.ConcurrencyLimit.Enabled {
+		// the stream lifetimes aren't the latencies of the adaptive limit, so the streams have their own static limit
+		streamConf := *conf.ConcurrencyLimit
+		streamConf.Mode = enums.StaticLimit
+
+		unaryInterceptors = append(unaryInterceptors, ConcurrencyLimitServerInterceptor(limiter.New("grpc_server", conf.ConcurrencyLimit)))
+		streamInterceptors = append(streamInterceptors, ConcurrencyLimitStreamServerInterceptor(limiter.New("grpc_server_stream", &streamConf)))
+	}
+
+	if len(conf.Compression.AcceptedEncodings) > 0 || len(conf.Compression.RejectedEncodings) > 0 {
+		unaryInterceptors = append(unaryInterceptors, CompressionUnaryServerInterceptor(&conf.Compression))
+		streamInterceptors = append(streamInterceptors, CompressionStreamServerInterceptor(&conf.Compression))
+	}
+
+	if conf.Deadlines != nil {
+		unaryInterceptors = append(unaryInterceptors, DeadlineServerInterceptor(conf.Deadlines))
+		streamInterceptors = append(streamInterceptors, DeadlineStreamServerInterceptor(conf.Deadlines))
+	}
+
+	unaryInterceptors = append(unaryInterceptors, ErrorServerInterceptor())
+	streamInterceptors = append(streamInterceptors, ErrorStreamServerInterceptor())
+
+	if conf.FaultInjection != nil && conf.FaultInjection.Enabled {
+		injector, err := fault.For(conf.FaultInjection)
+		if err != nil {
+			return nil, err
+		}
+
+		unaryInterceptors = append(unaryInterceptors, FaultServerInterceptor(injector))
+		streamInterceptors = append(streamInterceptors, FaultStreamServerInterceptor(injector))
+	}
+
 	if len(internalInterceptors) > 0 {
 		unaryInterceptors = append(unaryInterceptors, internalInterceptors...)
 	}
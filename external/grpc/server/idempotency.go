@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/dysnix/predictkube-libs/external/cache"
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+const (
+	DefaultIdempotencyWindow = 10 * time.Minute
+
+	idempotencyCachePrefix = "idempotency"
+)
+
+// the results of the calls failed with these codes aren't stored, the retries of them are executed again
+var transientCodes = map[codes.Code]struct{}{
+	codes.Canceled:          {},
+	codes.Unknown:           {},
+	codes.DeadlineExceeded:  {},
+	codes.ResourceExhausted: {},
+	codes.Aborted:           {},
+	codes.Internal:          {},
+	codes.Unavailable:       {},
+}
+
+// idempotentResult is the stored result of the first call with the idempotency key.
+type idempotentResult struct {
+	RequestHash  string `json:"request_hash"`
+	ResponseType string `json:"response_type,omitempty"`
+	Response     []byte `json:"response,omitempty"`
+	Status       []byte `json:"status,omitempty"`
+}
+
+// IdempotencyServerInterceptor executes the calls with the same idempotency key once, the duplicates get
+// the result of the first call stored in the cache for the window. The concurrent duplicates of the replica
+// wait for the first call, which isn't cancelled with its caller but is bounded by the window. The key reused
+// with a different request is rejected with FailedPrecondition. The result which can't be stored is logged
+// and returned as is, as the call is already executed.
+func IdempotencyServerInterceptor(conf *configs.Idempotency, c cache.Cache, logger *zap.SugaredLogger) grpc.UnaryServerInterceptor {
+	window := conf.Window
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+
+	group := &singleflight.Group{}
+
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		key := grpcC.MetadataValue(md, grpcC.IdempotencyKey)
+		if len(key) == 0 || !conf.Matches(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		// the keys are generated by the clients, so they are scoped by the cluster
+		cacheKey := fmt.Sprintf("%s:%s:%s:%s", idempotencyCachePrefix, grpcC.MetadataValue(md, grpcC.ClusterIDKey), info.FullMethod, key)
+
+		hash, err := requestHash(req)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "hash request: %v", err)
+		}
+
+		ch := group.DoChan(cacheKey, func() (interface{}, error) {
+			// the first caller may give up, e.g. by its deadline, while its duplicates still wait for the result
+			ctx, cancel := context.WithTimeout(withoutCancel{ctx}, window)
+			defer cancel()
+
+			stored := &idempotentResult{}
+			if err := c.Get(ctx, cacheKey, stored); err == nil {
+				return stored, nil
+			} else if !errors.Is(err, cache.ErrNil) {
+				return nil, status.Errorf(codes.Unavailable, "get idempotent result: %v", err)
+			}
+
+			resp, err := handler(ctx, req)
+
+			result, encodeErr := newIdempotentResult(hash, resp, err)
+			if encodeErr != nil {
+				return nil, status.Errorf(codes.Internal, "encode idempotent result: %v", encodeErr)
+			}
+
+			if _, transient := transientCodes[status.Code(err)]; !transient {
+				// the failed call would be retried by the client and executed again
+				if err := c.Set(ctx, result, cacheKey, window); err != nil {
+					logger.Warnw("store idempotent result", "grpc.method", info.FullMethod, "key", key, "error", err)
+				}
+			}
+
+			return result, nil
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case res := <-ch:
+			if res.Err != nil {
+				return nil, res.Err
+			}
+
+			result := res.Val.(*idempotentResult)
+			if result.RequestHash != hash {
+				return nil, status.Errorf(codes.FailedPrecondition, "idempotency key %q is used by a different request", key)
+			}
+
+			// every caller decodes its own copy of the response
+			return result.decode()
+		}
+	}
+}
+
+func requestHash(req interface{}) (string, error) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return "", nil
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func newIdempotentResult(hash string, resp interface{}, err error) (result *idempotentResult, encodeErr error) {
+	result = &idempotentResult{RequestHash: hash}
+
+	if err != nil {
+		result.Status, encodeErr = proto.Marshal(status.Convert(err).Proto())
+		return result, encodeErr
+	}
+
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("response %T isn't a proto message", resp)
+	}
+
+	result.ResponseType = string(msg.ProtoReflect().Descriptor().FullName())
+	result.Response, encodeErr = proto.Marshal(msg)
+
+	return result, encodeErr
+}
+
+func (r *idempotentResult) decode() (interface{}, error) {
+	if len(r.Status) > 0 {
+		st := &spb.Status{}
+		if err := proto.Unmarshal(r.Status, st); err != nil {
+			return nil, status.Errorf(codes.Internal, "decode idempotent status: %v", err)
+		}
+
+		return nil, status.FromProto(st).Err()
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(r.ResponseType))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "find idempotent response type: %v", err)
+	}
+
+	msg := mt.New().Interface()
+	if err = proto.Unmarshal(r.Response, msg); err != nil {
+		return nil, status.Errorf(codes.Internal, "decode idempotent response: %v", err)
+	}
+
+	return msg, nil
+}
+
+// withoutCancel keeps the values of the parent context, but not its deadline and cancellation.
+type withoutCancel struct {
+	context.Context
+}
+
+func (withoutCancel) Deadline() (deadline time.Time, ok bool) {
+	return time.Time{}, false
+}
+
+func (withoutCancel) Done() <-chan struct{} {
+	return nil
+}
+
+func (withoutCancel) Err() error {
+	return nil
+}
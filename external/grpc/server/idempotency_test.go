@@ -0,0 +1,283 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dysnix/predictkube-libs/external/cache/memory"
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+)
+
+const sendMetricsMethod = "/services.GatewaySaver/SendMetrics"
+
+type testCacheConfigs struct {
+	conf *configs.Cache
+}
+
+func (c testCacheConfigs) GetCache() *configs.Cache {
+	return c.conf
+}
+
+func newTestCache(t *testing.T) *memory.Cache {
+	c, err := memory.NewCache(
+		configs.SetCache(testCacheConfigs{conf: &configs.Cache{
+			GlobalTTL: configs.TTL{TTL: time.Minute},
+			Memory:    &configs.Memory{CleanupInterval: time.Minute},
+		}}),
+		configs.SetCacheLogger(zap.NewNop().Sugar()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = c.Stop()
+	})
+
+	return c
+}
+
+type failingSetCache struct {
+	*memory.Cache
+}
+
+func (c failingSetCache) Set(context.Context, interface{}, string, time.Duration) error {
+	return errors.New("cache is down")
+}
+
+func idempotentCtx(key string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcC.IdempotencyKey, key, grpcC.ClusterIDKey, "bsc-1"))
+}
+
+func TestIdempotencyServerInterceptor(t *testing.T) {
+	type call struct {
+		key     string
+		method  string
+		req     *pb.ReqSendMetrics
+		wantErr codes.Code
+	}
+
+	req := &pb.ReqSendMetrics{MetricsOffset: 1}
+
+	var cases = []struct {
+		name      string
+		handleErr error
+		calls     []call
+		wantCalls int64
+	}{
+		{
+			name: "duplicate is replayed",
+			calls: []call{
+				{key: "a", method: sendMetricsMethod, req: req},
+				{key: "a", method: sendMetricsMethod, req: req},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "different keys are executed",
+			calls: []call{
+				{key: "a", method: sendMetricsMethod, req: req},
+				{key: "b", method: sendMetricsMethod, req: req},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "calls without key are executed",
+			calls: []call{
+				{method: sendMetricsMethod, req: req},
+				{method: sendMetricsMethod, req: req},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "not configured method is executed",
+			calls: []call{
+				{key: "a", method: "/services.GatewaySaver/Other", req: req},
+				{key: "a", method: "/services.GatewaySaver/Other", req: req},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "key reused by a different request",
+			calls: []call{
+				{key: "a", method: sendMetricsMethod, req: req},
+				{key: "a", method: sendMetricsMethod, req: &pb.ReqSendMetrics{MetricsOffset: 2}, wantErr: codes.FailedPrecondition},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:      "error is replayed",
+			handleErr: status.Error(codes.InvalidArgument, "invalid metrics"),
+			calls: []call{
+				{key: "a", method: sendMetricsMethod, req: req, wantErr: codes.InvalidArgument},
+				{key: "a", method: sendMetricsMethod, req: req, wantErr: codes.InvalidArgument},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:      "transient error isn't stored",
+			handleErr: status.Error(codes.Unavailable, "storage is down"),
+			calls: []call{
+				{key: "a", method: sendMetricsMethod, req: req, wantErr: codes.Unavailable},
+				{key: "a", method: sendMetricsMethod, req: req, wantErr: codes.Unavailable},
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var calls int64
+
+			interceptor := IdempotencyServerInterceptor(&configs.Idempotency{
+				Enabled: true,
+				Methods: []string{sendMetricsMethod},
+			}, newTestCache(t), zap.NewNop().Sugar())
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				atomic.AddInt64(&calls, 1)
+				if c.handleErr != nil {
+					return nil, c.handleErr
+				}
+
+				return &pb.Header{ClusterId: "bsc-1"}, nil
+			}
+
+			for _, cl := range c.calls {
+				resp, err := interceptor(idempotentCtx(cl.key), cl.req, &grpc.UnaryServerInfo{FullMethod: cl.method}, handler)
+				if cl.wantErr != codes.OK {
+					assert.Equal(t, cl.wantErr, status.Code(err))
+					continue
+				}
+
+				require.NoError(t, err)
+				assert.True(t, proto.Equal(&pb.Header{ClusterId: "bsc-1"}, resp.(proto.Message)))
+			}
+
+			assert.Equal(t, c.wantCalls, atomic.LoadInt64(&calls))
+		})
+	}
+}
+
+func TestIdempotencyServerInterceptorConcurrentDuplicates(t *testing.T) {
+	var calls int64
+
+	interceptor := IdempotencyServerInterceptor(&configs.Idempotency{Enabled: true}, newTestCache(t), zap.NewNop().Sugar())
+
+	release := make(chan struct{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		<-release
+
+		return &pb.Header{ClusterId: "bsc-1"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := interceptor(idempotentCtx("a"), &pb.ReqSendMetrics{}, &grpc.UnaryServerInfo{FullMethod: sendMetricsMethod}, handler)
+			assert.NoError(t, err)
+			assert.Equal(t, "bsc-1", resp.(*pb.Header).GetClusterId())
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+}
+
+func TestIdempotencyServerInterceptorStoreFailure(t *testing.T) {
+	var calls int64
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	interceptor := IdempotencyServerInterceptor(&configs.Idempotency{Enabled: true}, failingSetCache{Cache: newTestCache(t)}, zap.New(core).Sugar())
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		return &pb.Header{ClusterId: "bsc-1"}, nil
+	}
+
+	// the executed call isn't reported as a retryable failure
+	resp, err := interceptor(idempotentCtx("a"), &pb.ReqSendMetrics{}, &grpc.UnaryServerInfo{FullMethod: sendMetricsMethod}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "bsc-1", resp.(*pb.Header).GetClusterId())
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+	assert.Equal(t, 1, logs.FilterMessage("store idempotent result").Len())
+}
+
+func TestIdempotencyServerInterceptorCancelledFirstCall(t *testing.T) {
+	var calls int64
+
+	interceptor := IdempotencyServerInterceptor(&configs.Idempotency{Enabled: true}, newTestCache(t), zap.NewNop().Sugar())
+	info := &grpc.UnaryServerInfo{FullMethod: sendMetricsMethod}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		close(started)
+
+		select {
+		case <-release:
+			return &pb.Header{ClusterId: "bsc-1"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(idempotentCtx("a"))
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := interceptor(ctx, &pb.ReqSendMetrics{}, info, handler)
+		first <- err
+	}()
+
+	<-started
+
+	duplicate := make(chan *pb.Header, 1)
+	go func() {
+		resp, err := interceptor(idempotentCtx("a"), &pb.ReqSendMetrics{}, info, handler)
+		assert.NoError(t, err)
+
+		header, _ := resp.(*pb.Header)
+		duplicate <- header
+	}()
+
+	// the first caller gives up while the duplicate waits for the same call
+	cancel()
+	assert.Equal(t, codes.Canceled, status.Code(<-first))
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case header := <-duplicate:
+		assert.Equal(t, "bsc-1", header.GetClusterId())
+	case <-time.After(time.Second):
+		t.Fatal("the duplicate didn't get the response")
+	}
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+}
@@ -3,76 +3,64 @@ package server
 import (
 	"context"
 	"errors"
-	"reflect"
-	"strings"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
-
-	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
-	"github.com/dysnix/predictkube-libs/external/http_transport"
-	pb "github.com/dysnix/predictkube-proto/external/proto/services"
 )
 
 const (
 	startTimeKey = "startTime"
 )
 
+// AuthLifecycleInterceptor observes the duration of the unary calls in milliseconds,
+// use TenantMetrics for the metrics labelled by the client identity.
 func AuthLifecycleInterceptor(authLifecycle prometheus.Histogram) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{},
-		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		resp, err = handler(http_transport.AddToContext(ctx, startTimeKey, time.Now().Round(time.Millisecond)), req)
-
-		if startTime, ok := http_transport.GetFromContext(ctx, startTimeKey).(time.Time); ok {
-			if authLifecycle != nil {
-				authLifecycle.Observe(float64(time.Since(startTime).Milliseconds()))
-			}
+	return ObserverUnaryServerInterceptor(ObserverFunc(func(_ context.Context, o *Observation) {
+		if authLifecycle != nil {
+			authLifecycle.Observe(float64(o.Duration.Milliseconds()))
 		}
-
-		return resp, err
-	}
+	}))
 }
 
+// InjectClientMetadataInterceptor merges the client identity of the request metadata
+// into the header of the request message, see MergeClientHeader.
 func InjectClientMetadataInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{},
 		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		st := reflect.TypeOf(req)
-		_, ok := st.MethodByName("GetHeader")
-		if ok {
-
-			header := pb.Header{}
-
-			md, ok := metadata.FromIncomingContext(ctx)
-			if ok {
-				for key, val := range md {
-					if strings.Contains(key, grpcC.ClusterIDKey) && len(val) > 0 {
-						header.ClusterId = val[0]
-						break
-					}
-				}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			InjectClientHeader(req, md)
+		}
 
-				if len(header.GetClusterId()) == 0 {
-					for key, val := range md {
-						if strings.Contains(key, grpcC.NameKey) && len(val) > 0 {
-							header.ClusterId = val[0]
-							break
-						}
-					}
-				}
-			}
+		return handler(ctx, req)
+	}
+}
 
-			var b interface{} = header
-			field := reflect.New(reflect.TypeOf(b))
-			field.Elem().Set(reflect.ValueOf(b))
-			reflect.ValueOf(req).Elem().FieldByName("Header").Set(field)
+// InjectClientMetadataStreamInterceptor merges the client identity into every message received by the stream.
+func InjectClientMetadataStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, ok := metadata.FromIncomingContext(ss.Context())
+		if !ok {
+			return handler(srv, ss)
 		}
 
-		resp, err = handler(ctx, req)
+		return handler(srv, &headerServerStream{ServerStream: ss, md: md})
+	}
+}
 
-		return resp, err
+type headerServerStream struct {
+	grpc.ServerStream
+	md metadata.MD
+}
+
+func (s *headerServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
 	}
+
+	InjectClientHeader(m, s.md)
+
+	return nil
 }
 
 func PanicServerInterceptor(panicHandler func(ctx context.Context, err error, params ...interface{}) error, params ...interface{}) grpc.UnaryServerInterceptor {
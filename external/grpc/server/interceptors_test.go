@@ -1,22 +1,270 @@
-package server
+package server_test
 
 import (
-	"reflect"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/cache"
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/fault"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/grpc/grpctest"
+	"github.com/dysnix/predictkube-libs/external/grpc/server"
 	pb "github.com/dysnix/predictkube-proto/external/proto/services"
 )
 
+type gatewaySaver struct {
+	pb.UnimplementedGatewaySaverServiceServer
+
+	header *pb.Header
+	err    error
+}
+
+func (g *gatewaySaver) GetMetricsOffset(_ context.Context, req *pb.ReqGetMetricsOffset) (*pb.ResGetMetricsOffset, error) {
+	g.header = req.GetHeader()
+
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	return &pb.ResGetMetricsOffset{CurrentOffset: 1}, nil
+}
+
+func (g *gatewaySaver) SendMetrics(context.Context, *pb.ReqSendMetrics) (*pb.ResSendMetrics, error) {
+	panic(errors.New("storage is gone"))
+}
+
+func newGatewaySaver(t *testing.T, opts ...grpctest.Option) (*gatewaySaver, pb.GatewaySaverServiceClient) {
+	srv := &gatewaySaver{}
+
+	s := grpctest.New(t, func(s *grpc.Server) {
+		pb.RegisterGatewaySaverServiceServer(s, srv)
+	}, append(opts, grpctest.WithServerInterceptors(server.InjectClientMetadataInterceptor()))...)
+
+	return srv, pb.NewGatewaySaverServiceClient(s.Conn)
+}
+
 func TestInjectClientMetadataInterceptor(t *testing.T) {
-	var req interface{} = &pb.ReqSendMetrics{}
-	st := reflect.TypeOf(req)
-	_, ok := st.MethodByName("GetHeader")
-	if ok {
-		var b interface{} = pb.Header{ClusterId: "bsc-1"}
-		field := reflect.New(reflect.TypeOf(b))
-		field.Elem().Set(reflect.ValueOf(b))
-		reflect.ValueOf(req).Elem().FieldByName("Header").Set(field)
+	var cases = []struct {
+		name          string
+		opts          []grpctest.Option
+		callMD        metadata.MD
+		req           *pb.ReqGetMetricsOffset
+		wantClusterID string
+	}{
+		{
+			name:          "header is created",
+			opts:          []grpctest.Option{grpctest.WithClusterID("bsc-1")},
+			req:           &pb.ReqGetMetricsOffset{},
+			wantClusterID: "bsc-1",
+		},
+		{
+			name:          "metadata overrides the cluster id",
+			opts:          []grpctest.Option{grpctest.WithClusterID("bsc-1")},
+			req:           &pb.ReqGetMetricsOffset{Header: &pb.Header{ClusterId: "eth-1"}},
+			wantClusterID: "bsc-1",
+		},
+		{
+			name:          "call metadata overrides the harness one",
+			opts:          []grpctest.Option{grpctest.WithClusterID("bsc-1")},
+			callMD:        metadata.Pairs(grpcC.ClusterIDKey, "eth-1"),
+			req:           &pb.ReqGetMetricsOffset{},
+			wantClusterID: "eth-1",
+		},
+		{
+			name:          "client sent header is kept",
+			opts:          []grpctest.Option{grpctest.WithToken("token")},
+			req:           &pb.ReqGetMetricsOffset{Header: &pb.Header{ClusterId: "eth-1"}},
+			wantClusterID: "eth-1",
+		},
+		{
+			name:          "client name isn't the cluster id",
+			opts:          []grpctest.Option{grpctest.WithMetadata(grpcC.NameKey, "client")},
+			req:           &pb.ReqGetMetricsOffset{},
+			wantClusterID: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv, client := newGatewaySaver(t, c.opts...)
+
+			ctx := context.Background()
+			if c.callMD != nil {
+				ctx = metadata.NewOutgoingContext(ctx, c.callMD)
+			}
+
+			_, err := client.GetMetricsOffset(ctx, c.req)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.wantClusterID, srv.header.GetClusterId())
+		})
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	srv, client := newGatewaySaver(t)
+
+	srv.err = cache.ErrNil
+	_, err := client.GetMetricsOffset(context.Background(), &pb.ReqGetMetricsOffset{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.SendMetrics(context.Background(), &pb.ReqSendMetrics{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestFaultServerInterceptor(t *testing.T) {
+	injector, err := fault.New(&configs.FaultInjection{
+		Enabled: true,
+		Active:  true,
+		Rules: []configs.FaultRule{
+			{
+				Methods:    []string{"services.GatewaySaverService/GetMetricsOffset"},
+				Clusters:   []string{"bsc-1"},
+				Percentage: 100,
+				Code:       "UNAVAILABLE",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, client := newGatewaySaver(t, grpctest.WithServerInterceptors(server.FaultServerInterceptor(injector)))
+
+	var cases = []struct {
+		name      string
+		clusterID string
+		active    bool
+		want      codes.Code
+	}{
+		{
+			name:      "fault of the cluster",
+			clusterID: "bsc-1",
+			active:    true,
+			want:      codes.Unavailable,
+		},
+		{
+			name:      "other cluster",
+			clusterID: "eth-1",
+			active:    true,
+			want:      codes.OK,
+		},
+		{
+			name:      "inactive injection",
+			clusterID: "bsc-1",
+			want:      codes.OK,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			injector.SetActive(c.active)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), grpcC.ClusterIDKey, c.clusterID)
+
+			_, err := client.GetMetricsOffset(ctx, &pb.ReqGetMetricsOffset{})
+			assert.Equal(t, c.want, status.Code(err))
+		})
+	}
+}
+
+func TestLoggingInterceptors(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	_, client := newGatewaySaver(t,
+		grpctest.WithConfigs(&configs.GRPC{Logging: &configs.Logging{Enabled: true, SampleRate: 1}}, &configs.Base{}),
+		grpctest.WithLogger(zap.New(core).Sugar()),
+		grpctest.WithClusterID("bsc-1"),
+	)
+
+	_, err := client.GetMetricsOffset(context.Background(), &pb.ReqGetMetricsOffset{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, logs.FilterMessage("finished unary call").FilterField(zap.String("cluster_id", "bsc-1")).Len())
+	assert.Equal(t, 1, logs.FilterMessage("finished client unary call").Len())
+}
+
+// hs256Token signs the claims with the secret, the tokens expire in an hour.
+func hs256Token(t *testing.T, secret []byte, claims jwt.MapClaims) string {
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestAuthzInterceptors(t *testing.T) {
+	dir := t.TempDir()
+
+	policyPath := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte(`
+rules:
+  - name: agents
+    roles: ["agent"]
+    methods: ["services.GatewaySaverService/GetMetricsOffset"]
+`), 0600))
+
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("secret"), 0600))
+
+	conf := &configs.GRPC{Authz: &configs.Authz{
+		Enabled:    true,
+		PolicyPath: policyPath,
+		JWT:        &configs.JWTAuth{SecretFile: secretFile},
+	}}
+
+	var cases = []struct {
+		name  string
+		token string
+		want  codes.Code
+	}{
+		{
+			name:  "role of the rule",
+			token: hs256Token(t, []byte("secret"), jwt.MapClaims{"sub": "agent-1", "roles": []string{"agent"}}),
+			want:  codes.OK,
+		},
+		{
+			name:  "other role",
+			token: hs256Token(t, []byte("secret"), jwt.MapClaims{"sub": "viewer-1", "roles": []string{"viewer"}}),
+			want:  codes.PermissionDenied,
+		},
+		{
+			name:  "forged token",
+			token: hs256Token(t, []byte("other"), jwt.MapClaims{"sub": "agent-1", "roles": []string{"agent"}}),
+			want:  codes.PermissionDenied,
+		},
+		{
+			name: "anonymous call",
+			want: codes.PermissionDenied,
+		},
 	}
 
-	t.Log(req.(*pb.ReqSendMetrics).Header.ClusterId)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := []grpctest.Option{grpctest.WithConfigs(conf, &configs.Base{})}
+			if len(c.token) > 0 {
+				opts = append(opts, grpctest.WithToken(c.token))
+			}
+
+			_, client := newGatewaySaver(t, opts...)
+
+			_, err := client.GetMetricsOffset(context.Background(), &pb.ReqGetMetricsOffset{})
+			assert.Equal(t, c.want, status.Code(err))
+		})
+	}
 }
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/limiter"
+)
+
+// ConcurrencyLimitServerInterceptor rejects the calls over the concurrency limit with the ResourceExhausted code,
+// the calls failed by the overload (deadline exceeded, resource exhausted or unavailable) or panicked shrink
+// the adaptive limit.
+func ConcurrencyLimitServerInterceptor(l *limiter.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		done, err := l.Acquire(info.FullMethod, incomingClientName(ctx))
+		if err != nil {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+
+		// the slot is released by the panicking handler too, the panic is the failure
+		success, start := false, time.Now()
+		defer func() {
+			done(success, time.Since(start))
+		}()
+
+		resp, err = handler(ctx, req)
+		success = !overloaded(err)
+
+		return resp, err
+	}
+}
+
+// ConcurrencyLimitStreamServerInterceptor limits the concurrent streams, see ConcurrencyLimitServerInterceptor.
+// The streams live as long as the clients need them, so the limiter of the streams should be the static one
+// and separate from the limiter of the unary calls, see SetGrpcServerOptions.
+func ConcurrencyLimitStreamServerInterceptor(l *limiter.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		done, err := l.Acquire(info.FullMethod, incomingClientName(ss.Context()))
+		if err != nil {
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+
+		success, start := false, time.Now()
+		defer func() {
+			done(success, time.Since(start))
+		}()
+
+		err = handler(srv, ss)
+		success = !overloaded(err)
+
+		return err
+	}
+}
+
+func overloaded(err error) bool {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.ResourceExhausted, codes.Unavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+func incomingClientName(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return grpcC.MetadataValue(md, grpcC.NameKey)
+}
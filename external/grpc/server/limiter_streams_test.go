@@ -0,0 +1,46 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+	"github.com/dysnix/predictkube-libs/external/grpc/grpctest"
+)
+
+func TestConcurrencyLimitStreams(t *testing.T) {
+	s := grpctest.New(t, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, health.NewServer())
+	}, grpctest.WithConfigs(&configs.GRPC{
+		ConcurrencyLimit: &configs.ConcurrencyLimit{Enabled: true, Mode: enums.Gradient, Limit: 1, MaxLimit: 1},
+	}, &configs.Base{}))
+
+	client := healthpb.NewHealthClient(s.Conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// the open stream doesn't take the slot of the unary calls
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	// the streams have their own limit
+	other, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = other.Recv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
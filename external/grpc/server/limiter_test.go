@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+	"github.com/dysnix/predictkube-libs/external/limiter"
+)
+
+func TestConcurrencyLimitReleasesPanics(t *testing.T) {
+	l := limiter.New("test_panics", &configs.ConcurrencyLimit{Enabled: true, Mode: enums.AIMD, Limit: 2, MinLimit: 1})
+
+	interceptor := ConcurrencyLimitServerInterceptor(l)
+	streamInterceptor := ConcurrencyLimitStreamServerInterceptor(l)
+
+	for i := 0; i < 5; i++ {
+		assert.Panics(t, func() {
+			_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: sendMetricsMethod},
+				func(context.Context, interface{}) (interface{}, error) {
+					panic(errors.New("storage is gone"))
+				})
+		})
+
+		assert.Panics(t, func() {
+			_ = streamInterceptor(nil, &testServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: sendMetricsMethod},
+				func(interface{}, grpc.ServerStream) error {
+					panic(errors.New("storage is gone"))
+				})
+		})
+	}
+
+	assert.Zero(t, l.Inflight())
+	// the panics are the failures
+	assert.Equal(t, 1, l.Limit())
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: sendMetricsMethod},
+		func(context.Context, interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+	require.NoError(t, err)
+}
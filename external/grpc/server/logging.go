@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+const (
+	unaryCallMsg  = "finished unary call"
+	streamCallMsg = "finished streaming call"
+)
+
+func LoggingUnaryServerInterceptor(logger *zap.SugaredLogger, conf *configs.Logging) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+
+		resp, err = handler(ctx, req)
+
+		code := status.Code(err)
+		if grpcC.ShouldLog(conf, info.FullMethod, code) {
+			grpcC.LogCall(logger, code, unaryCallMsg, append(callFields(ctx, info.FullMethod, start, err),
+				"grpc.request_size", grpcC.MessageSize(req),
+				"grpc.response_size", grpcC.MessageSize(resp),
+			)...)
+		}
+
+		return resp, err
+	}
+}
+
+func LoggingStreamServerInterceptor(logger *zap.SugaredLogger, conf *configs.Logging) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		wrapped := &sizeServerStream{ServerStream: ss}
+
+		err = handler(srv, wrapped)
+
+		code := status.Code(err)
+		if grpcC.ShouldLog(conf, info.FullMethod, code) {
+			grpcC.LogCall(logger, code, streamCallMsg,
+				append(callFields(ss.Context(), info.FullMethod, start, err), wrapped.stats.Fields()...)...)
+		}
+
+		return err
+	}
+}
+
+func callFields(ctx context.Context, fullMethod string, start time.Time, err error) []interface{} {
+	fields := []interface{}{
+		"grpc.method", fullMethod,
+		"grpc.code", status.Code(err).String(),
+		"grpc.duration", time.Since(start),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, "peer.address", p.Addr.String())
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		fields = append(fields,
+			"cluster_id", grpcC.MetadataValue(md, grpcC.ClusterIDKey),
+			"client_name", grpcC.MetadataValue(md, grpcC.NameKey),
+		)
+	}
+
+	if err != nil {
+		fields = append(fields, "error", err.Error())
+	}
+
+	return fields
+}
+
+type sizeServerStream struct {
+	grpc.ServerStream
+
+	stats grpcC.StreamStats
+}
+
+func (s *sizeServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.stats.Received(m)
+	}
+
+	return err
+}
+
+func (s *sizeServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.stats.Sent(m)
+	}
+
+	return err
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/http_transport"
+)
+
+// Observation describes a finished server call.
+type Observation struct {
+	FullMethod string
+	ClusterID  string
+	ClientName string
+	Err        error
+	// StartTime is truncated to milliseconds, the Duration is measured by the monotonic clock
+	StartTime     time.Time
+	Duration      time.Duration
+	ReceivedBytes int
+	SentBytes     int
+
+	start time.Time
+}
+
+// Observer is notified about every finished server call by the observer interceptors.
+type Observer interface {
+	Observe(ctx context.Context, o *Observation)
+}
+
+// ObserverFunc is an adapter to use the ordinary functions as observers.
+type ObserverFunc func(ctx context.Context, o *Observation)
+
+func (f ObserverFunc) Observe(ctx context.Context, o *Observation) {
+	f(ctx, o)
+}
+
+// ObserverUnaryServerInterceptor notifies the observers about every finished unary call,
+// the start time of the call is available to the handlers by the startTime context key.
+func ObserverUnaryServerInterceptor(observers ...Observer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		o := newObservation(ctx, info.FullMethod)
+		o.ReceivedBytes = grpcC.MessageSize(req)
+
+		resp, err = handler(http_transport.AddToContext(ctx, startTimeKey, o.start), req)
+
+		o.SentBytes = grpcC.MessageSize(resp)
+		o.finish(ctx, err, observers)
+
+		return resp, err
+	}
+}
+
+// ObserverStreamServerInterceptor notifies the observers about every finished stream,
+// the message sizes are summed over all the messages of the stream.
+func ObserverStreamServerInterceptor(observers ...Observer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		stream := &observedServerStream{ServerStream: ss, o: newObservation(ctx, info.FullMethod)}
+
+		err := handler(srv, stream)
+
+		stream.o.finish(ctx, err, observers)
+
+		return err
+	}
+}
+
+func newObservation(ctx context.Context, fullMethod string) *Observation {
+	start := time.Now()
+
+	o := &Observation{
+		FullMethod: fullMethod,
+		StartTime:  start.Truncate(time.Millisecond),
+		start:      start,
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		o.ClusterID = grpcC.MetadataValue(md, grpcC.ClusterIDKey)
+		o.ClientName = grpcC.MetadataValue(md, grpcC.NameKey)
+	}
+
+	return o
+}
+
+func (o *Observation) finish(ctx context.Context, err error, observers []Observer) {
+	o.Err = err
+	o.Duration = time.Since(o.start)
+
+	for _, observer := range observers {
+		if observer != nil {
+			observer.Observe(ctx, o)
+		}
+	}
+}
+
+// Code returns the gRPC status code of the call error.
+func (o *Observation) Code() string {
+	return status.Code(o.Err).String()
+}
+
+type observedServerStream struct {
+	grpc.ServerStream
+	o *Observation
+}
+
+func (s *observedServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	s.o.ReceivedBytes += grpcC.MessageSize(m)
+
+	return nil
+}
+
+func (s *observedServerStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+
+	s.o.SentBytes += grpcC.MessageSize(m)
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestObservationTimes(t *testing.T) {
+	var observations []*Observation
+
+	interceptor := ObserverUnaryServerInterceptor(ObserverFunc(func(_ context.Context, o *Observation) {
+		observations = append(observations, o)
+	}))
+
+	for i := 0; i < 100; i++ {
+		// the sub-millisecond calls don't get the negative latency of the start rounded into the future
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/services.GatewaySaverService/GetMetricsOffset"},
+			func(context.Context, interface{}) (interface{}, error) {
+				return nil, nil
+			})
+		assert.NoError(t, err)
+	}
+
+	for _, o := range observations {
+		assert.GreaterOrEqual(t, int64(o.Duration), int64(0))
+		assert.False(t, o.StartTime.After(o.start))
+		assert.Equal(t, o.StartTime, o.StartTime.Truncate(time.Millisecond))
+	}
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+const (
+	DefaultRolesClaim  = "roles"
+	DefaultScopesClaim = "scope"
+)
+
+var (
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrUnknownAPIKey = errors.New("unknown api key")
+)
+
+// PrincipalExtractor authenticates the principals of the calls by the JWT token of the TokenKey metadata
+// and the API key of the APIKey metadata, the principal of both of them has the subject of the token
+// and the scopes of both.
+type PrincipalExtractor struct {
+	jwt     *jwtVerifier
+	apiKeys map[[sha256.Size]byte]configs.APIKeyAuth
+	logger  *zap.SugaredLogger
+}
+
+// NewPrincipalExtractor returns the extractor of the configs, the JWT tokens aren't accepted without the JWT configs.
+func NewPrincipalExtractor(conf *configs.Authz, logger *zap.SugaredLogger) (*PrincipalExtractor, error) {
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+
+	out := &PrincipalExtractor{
+		apiKeys: make(map[[sha256.Size]byte]configs.APIKeyAuth, len(conf.APIKeys)),
+		logger:  logger,
+	}
+
+	if conf.JWT != nil {
+		v, err := newJWTVerifier(conf.JWT)
+		if err != nil {
+			return nil, err
+		}
+
+		out.jwt = v
+	}
+
+	for _, key := range conf.APIKeys {
+		digest, err := hex.DecodeString(key.SHA256)
+		if err != nil || len(digest) != sha256.Size {
+			return nil, fmt.Errorf("api key %q has invalid sha256 digest", key.Name)
+		}
+
+		var k [sha256.Size]byte
+		copy(k[:], digest)
+		out.apiKeys[k] = key
+	}
+
+	return out, nil
+}
+
+// Principal returns the principal of the incoming metadata or nil for the calls without the credentials,
+// the invalid credentials are the error.
+func (e *PrincipalExtractor) Principal(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var p *Principal
+
+	if token := grpcC.MetadataValue(md, grpcC.TokenKey); len(token) > 0 && e.jwt != nil {
+		var err error
+		if p, err = e.jwt.principal(token); err != nil {
+			return nil, err
+		}
+	}
+
+	if apiKey := grpcC.MetadataValue(md, grpcC.APIKey); len(apiKey) > 0 {
+		key, ok := e.apiKeys[sha256.Sum256([]byte(apiKey))]
+		if !ok {
+			return nil, ErrUnknownAPIKey
+		}
+
+		if p == nil {
+			p = &Principal{Subject: key.Name}
+		}
+
+		p.Scopes = append(p.Scopes, key.Scopes...)
+	}
+
+	return p, nil
+}
+
+// UnaryServerInterceptor puts the principal into the call context for the Authorizer, the calls with the invalid
+// credentials go on without the principal, so they are allowed only by the rules without any conditions.
+func (e *PrincipalExtractor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		return handler(e.contextWithPrincipal(ctx, info.FullMethod), req)
+	}
+}
+
+func (e *PrincipalExtractor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: e.contextWithPrincipal(ss.Context(), info.FullMethod)})
+	}
+}
+
+func (e *PrincipalExtractor) contextWithPrincipal(ctx context.Context, fullMethod string) context.Context {
+	p, err := e.Principal(ctx)
+	if err != nil {
+		e.logger.Warnw("authentication failed", "grpc.method", fullMethod, "error", err)
+		return ctx
+	}
+
+	if p == nil {
+		return ctx
+	}
+
+	return ContextWithPrincipal(ctx, p)
+}
+
+// jwtVerifier verifies the signature and the registered claims of the JWT tokens, the tokens
+// have to expire and the algorithm is fixed by the key, so the tokens can't switch it, e.g. to "none".
+type jwtVerifier struct {
+	parser      *jwt.Parser
+	key         interface{}
+	issuer      string
+	audience    string
+	rolesClaim  string
+	scopesClaim string
+}
+
+func newJWTVerifier(conf *configs.JWTAuth) (*jwtVerifier, error) {
+	v := &jwtVerifier{
+		issuer:      conf.Issuer,
+		audience:    conf.Audience,
+		rolesClaim:  conf.RolesClaim,
+		scopesClaim: conf.ScopesClaim,
+	}
+
+	if len(v.rolesClaim) == 0 {
+		v.rolesClaim = DefaultRolesClaim
+	}
+
+	if len(v.scopesClaim) == 0 {
+		v.scopesClaim = DefaultScopesClaim
+	}
+
+	var method jwt.SigningMethod
+
+	if len(conf.SecretFile) > 0 {
+		secret, err := os.ReadFile(conf.SecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt secret: %w", err)
+		}
+
+		method, v.key = jwt.SigningMethodHS256, bytes.TrimSpace(secret)
+	} else {
+		data, err := os.ReadFile(conf.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt public key: %w", err)
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("jwt public key: no PEM data in %s", conf.PublicKeyFile)
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt public key: %w", err)
+		}
+
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			method = jwt.SigningMethodRS256
+		case *ecdsa.PublicKey:
+			if k.Curve.Params().BitSize != 256 {
+				return nil, fmt.Errorf("jwt public key: unsupported curve %s", k.Curve.Params().Name)
+			}
+
+			method = jwt.SigningMethodES256
+		default:
+			return nil, fmt.Errorf("jwt public key: unsupported key type %T", key)
+		}
+
+		v.key = key
+	}
+
+	v.parser = jwt.NewParser(jwt.WithValidMethods([]string{method.Alg()}), jwt.WithJSONNumber())
+
+	return v, nil
+}
+
+func (v *jwtVerifier) principal(token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+
+	if _, err := v.parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return v.key, nil
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if err := v.verifyClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+
+	p := &Principal{
+		Roles:  stringsClaim(claims[v.rolesClaim]),
+		Scopes: stringsClaim(claims[v.scopesClaim]),
+		Claims: make(map[string]string, len(claims)),
+	}
+
+	for name, value := range claims {
+		switch value := value.(type) {
+		case string:
+			p.Claims[name] = value
+		case json.Number, bool:
+			p.Claims[name] = fmt.Sprint(value)
+		}
+	}
+
+	p.Subject = p.Claims["sub"]
+
+	return p, nil
+}
+
+// verifyClaims requires the numeric exp claim and checks the optional ones, the parser accepts
+// the tokens without exp and the claims of the other types fail the checks.
+func (v *jwtVerifier) verifyClaims(claims jwt.MapClaims, now time.Time) error {
+	if !claims.VerifyExpiresAt(now.Unix(), true) {
+		return fmt.Errorf("%w: token is expired or has no expiration", ErrInvalidToken)
+	}
+
+	if !claims.VerifyNotBefore(now.Unix(), false) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+
+	if len(v.issuer) > 0 && !claims.VerifyIssuer(v.issuer, true) {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+
+	if len(v.audience) > 0 && !claims.VerifyAudience(v.audience, true) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+// stringsClaim returns the items of the array claim or the space-delimited string claim.
+func stringsClaim(claim interface{}) []string {
+	switch claim := claim.(type) {
+	case string:
+		return strings.Fields(claim)
+	case []interface{}:
+		out := make([]string, 0, len(claim))
+		for _, item := range claim {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+
+		return out
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+func signJWT(t *testing.T, alg string, key interface{}, claims map[string]interface{}) string {
+	if alg == jwt.SigningMethodNone.Alg() {
+		key = jwt.UnsafeAllowNoneSignatureType
+	}
+
+	token, err := jwt.NewWithClaims(jwt.GetSigningMethod(alg), jwt.MapClaims(claims)).SignedString(key)
+	require.NoError(t, err)
+
+	return token
+}
+
+func writePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	return path
+}
+
+func TestPrincipalExtractor(t *testing.T) {
+	secret := []byte("secret")
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, append(secret, '\n'), 0600))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	apiKey := sha256.Sum256([]byte("api-key"))
+	hsConf := &configs.Authz{
+		JWT:     &configs.JWTAuth{SecretFile: secretFile, Issuer: "predictkube", Audience: "gateway"},
+		APIKeys: []configs.APIKeyAuth{{Name: "dashboard", SHA256: hex.EncodeToString(apiKey[:]), Scopes: []string{"metrics:read"}}},
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	claims := map[string]interface{}{
+		"sub":   "agent-1",
+		"iss":   "predictkube",
+		"aud":   []string{"gateway"},
+		"exp":   exp,
+		"roles": []string{"agent"},
+		"scope": "metrics:write",
+		"tier":  "pro",
+	}
+
+	with := func(changes map[string]interface{}) map[string]interface{} {
+		out := map[string]interface{}{}
+		for k, v := range claims {
+			out[k] = v
+		}
+
+		for k, v := range changes {
+			if v == nil {
+				delete(out, k)
+				continue
+			}
+
+			out[k] = v
+		}
+
+		return out
+	}
+
+	var cases = []struct {
+		name    string
+		conf    *configs.Authz
+		md      metadata.MD
+		want    *Principal
+		wantErr error
+	}{
+		{
+			name: "hmac token",
+			conf: hsConf,
+			md:   metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, claims)),
+			want: &Principal{Subject: "agent-1", Roles: []string{"agent"}, Scopes: []string{"metrics:write"}},
+		},
+		{
+			name: "rsa token",
+			conf: &configs.Authz{JWT: &configs.JWTAuth{PublicKeyFile: writePublicKey(t, &rsaKey.PublicKey)}},
+			md:   metadata.Pairs(grpcC.TokenKey, signJWT(t, "RS256", rsaKey, claims)),
+			want: &Principal{Subject: "agent-1", Roles: []string{"agent"}, Scopes: []string{"metrics:write"}},
+		},
+		{
+			name: "ecdsa token",
+			conf: &configs.Authz{JWT: &configs.JWTAuth{PublicKeyFile: writePublicKey(t, &ecKey.PublicKey)}},
+			md:   metadata.Pairs(grpcC.TokenKey, signJWT(t, "ES256", ecKey, claims)),
+			want: &Principal{Subject: "agent-1", Roles: []string{"agent"}, Scopes: []string{"metrics:write"}},
+		},
+		{
+			name:    "wrong signature",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", []byte("other"), claims)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "algorithm of the other key",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.TokenKey, signJWT(t, "none", nil, claims)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "expired token",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, with(map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()}))),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "string expiration",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, with(map[string]interface{}{"exp": fmt.Sprint(exp)}))),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "no expiration",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, with(map[string]interface{}{"exp": nil}))),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "not valid yet",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, with(map[string]interface{}{"nbf": time.Now().Add(time.Minute).Unix()}))),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "string not before",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, with(map[string]interface{}{"nbf": "0"}))),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "other issuer",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, with(map[string]interface{}{"iss": "other"}))),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "other audience",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, with(map[string]interface{}{"aud": "other"}))),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "api key scopes",
+			conf: hsConf,
+			md:   metadata.Pairs(grpcC.APIKey, "api-key"),
+			want: &Principal{Subject: "dashboard", Scopes: []string{"metrics:read"}},
+		},
+		{
+			name: "token and api key",
+			conf: hsConf,
+			md:   metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, claims), grpcC.APIKey, "api-key"),
+			want: &Principal{Subject: "agent-1", Roles: []string{"agent"}, Scopes: []string{"metrics:write", "metrics:read"}},
+		},
+		{
+			name:    "unknown api key",
+			conf:    hsConf,
+			md:      metadata.Pairs(grpcC.APIKey, "other"),
+			wantErr: ErrUnknownAPIKey,
+		},
+		{
+			name: "token without jwt configs",
+			conf: &configs.Authz{},
+			md:   metadata.Pairs(grpcC.TokenKey, signJWT(t, "HS256", secret, claims)),
+		},
+		{
+			name: "anonymous call",
+			conf: hsConf,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, err := NewPrincipalExtractor(c.conf, nil)
+			require.NoError(t, err)
+
+			p, err := e.Principal(metadata.NewIncomingContext(context.Background(), c.md))
+			if c.wantErr != nil {
+				assert.ErrorIs(t, err, c.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if c.want == nil {
+				assert.Nil(t, p)
+				return
+			}
+
+			require.NotNil(t, p)
+			assert.Equal(t, c.want.Subject, p.Subject)
+			assert.Equal(t, c.want.Roles, p.Roles)
+			assert.Equal(t, c.want.Scopes, p.Scopes)
+
+			if p.Claims != nil {
+				assert.Equal(t, "pro", p.Claims["tier"])
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/grpc/response_cache"
+)
+
+// ResponseCacheServerInterceptor returns the cached responses of the configured methods and stores
+// the successful ones, the successful calls of the mutating methods invalidate the cached responses
+// of their cluster. The cache errors don't fail the calls.
+func ResponseCacheServerInterceptor(c *response_cache.Cache) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		clusterID := grpcC.MetadataValue(md, grpcC.ClusterIDKey)
+
+		if cached, err := c.Get(ctx, info.FullMethod, clusterID, msg, nil); err == nil && cached != nil {
+			return cached, nil
+		}
+
+		if resp, err = handler(ctx, req); err != nil {
+			return resp, err
+		}
+
+		if out, ok := resp.(proto.Message); ok {
+			_ = c.Set(ctx, info.FullMethod, clusterID, msg, out)
+		}
+
+		_ = c.InvalidateAfter(ctx, info.FullMethod, clusterID)
+
+		return resp, nil
+	}
+}
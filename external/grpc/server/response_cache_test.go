@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+	"github.com/dysnix/predictkube-libs/external/grpc/response_cache"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+)
+
+const (
+	getSettingsMethod    = "/services.Settings/Get"
+	updateSettingsMethod = "/services.Settings/Update"
+)
+
+func TestResponseCacheServerInterceptor(t *testing.T) {
+	type call struct {
+		method    string
+		clusterID string
+		offset    uint64
+	}
+
+	get := func(clusterID string, offset uint64) call {
+		return call{method: getSettingsMethod, clusterID: clusterID, offset: offset}
+	}
+
+	var cases = []struct {
+		name      string
+		calls     []call
+		wantCalls int64
+	}{
+		{
+			name:      "identical requests hit the cache",
+			calls:     []call{get("bsc-1", 1), get("bsc-1", 1), get("bsc-1", 1)},
+			wantCalls: 1,
+		},
+		{
+			name:      "requests are keyed by encoding",
+			calls:     []call{get("bsc-1", 1), get("bsc-1", 2)},
+			wantCalls: 2,
+		},
+		{
+			name:      "requests are keyed by cluster",
+			calls:     []call{get("bsc-1", 1), get("eth-1", 1)},
+			wantCalls: 2,
+		},
+		{
+			name:      "mutating call invalidates the cluster",
+			calls:     []call{get("bsc-1", 1), {method: updateSettingsMethod, clusterID: "bsc-1"}, get("bsc-1", 1)},
+			wantCalls: 3,
+		},
+		{
+			name:      "mutating call keeps other clusters",
+			calls:     []call{get("eth-1", 1), {method: updateSettingsMethod, clusterID: "bsc-1"}, get("eth-1", 1)},
+			wantCalls: 2,
+		},
+		{
+			name:      "not cached method",
+			calls:     []call{{method: "/services.Other/Get"}, {method: "/services.Other/Get"}},
+			wantCalls: 2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var calls int64
+
+			interceptor := ResponseCacheServerInterceptor(response_cache.New(&configs.ResponseCache{
+				Enabled:       true,
+				Methods:       map[string]time.Duration{"services.Settings/Get": time.Minute},
+				InvalidatedBy: map[string][]string{"services.Settings/Update": {"services.Settings/Get"}},
+			}, newTestCache(t), response_cache.ServerSide))
+
+			for _, cl := range c.calls {
+				ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcC.ClusterIDKey, cl.clusterID))
+
+				resp, err := interceptor(ctx, &pb.ReqSendMetrics{MetricsOffset: cl.offset}, &grpc.UnaryServerInfo{FullMethod: cl.method},
+					func(ctx context.Context, req interface{}) (interface{}, error) {
+						atomic.AddInt64(&calls, 1)
+						return &pb.Header{ClusterId: cl.clusterID}, nil
+					})
+				require.NoError(t, err)
+				assert.Equal(t, cl.clusterID, resp.(*pb.Header).GetClusterId())
+			}
+
+			assert.Equal(t, c.wantCalls, atomic.LoadInt64(&calls))
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	pb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+const (
+	DefaultShutdownTimeout = 10 * time.Second
+)
+
+var (
+	_ Health = (*GrpcServer)(nil)
+)
+
+// GrpcServer implements the Server and Health interfaces with the standard grpc health service.
+type GrpcServer struct {
+	*health.Server
+
+	conf   configs.SingleGetter
+	logger *zap.SugaredLogger
+	server *grpc.Server
+
+	registers    []func(*grpc.Server)
+	interceptors []grpc.UnaryServerInterceptor
+
+	drainer  Drainer
+	stopOnce sync.Once
+}
+
+func NewGrpcServer(options ...configs.GrpcServerOption) (out *GrpcServer, err error) {
+	out = &GrpcServer{
+		Server: health.NewServer(),
+	}
+
+	for _, op := range options {
+		err := op(out)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if out.conf == nil || out.conf.GetGrpc() == nil || out.conf.GetBase() == nil {
+		return nil, errors.New("grpc server requires the base and grpc configs")
+	}
+
+	if out.logger == nil {
+		out.logger = zap.NewNop().Sugar()
+	}
+
+	serverOpts, err := setGrpcServerOptions(out.conf.GetGrpc(), out.conf.GetBase(), out.logger, &out.drainer, out.interceptors...)
+	if err != nil {
+		return nil, err
+	}
+
+	out.server = grpc.NewServer(serverOpts...)
+
+	pb.RegisterHealthServer(out.server, out)
+
+	for _, register := range out.registers {
+		register(out.server)
+	}
+
+	if out.conf.GetGrpc().UseReflection {
+		reflection.Register(out.server)
+	}
+
+	// all the services aren't serving until the server is started
+	out.setAllServingStatus(pb.HealthCheckResponse_NOT_SERVING)
+
+	return out, nil
+}
+
+func (s *GrpcServer) SetConfigs(configs configs.SingleGetter) {
+	s.conf = configs
+}
+
+func (s *GrpcServer) SetLogger(lg *zap.SugaredLogger) {
+	s.logger = lg
+}
+
+func (s *GrpcServer) AddServices(registers ...func(*grpc.Server)) {
+	s.registers = append(s.registers, registers...)
+}
+
+func (s *GrpcServer) AddInterceptors(interceptors ...grpc.UnaryServerInterceptor) {
+	s.interceptors = append(s.interceptors, interceptors...)
+}
+
+// GrpcServer returns the underlying grpc server, the services must be registered before Start.
+func (s *GrpcServer) GrpcServer() *grpc.Server {
+	return s.server
+}
+
+func (s *GrpcServer) Start() <-chan error {
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(errCh)
+
+		addr := fmt.Sprintf("%s:%d", s.conf.GetGrpc().Conn.Host, s.conf.GetGrpc().Conn.Port)
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			errCh <- err
+			return
+		}
+
+		s.setAllServingStatus(pb.HealthCheckResponse_SERVING)
+		s.logger.Infof("✔️ GRPC server started on %s.", addr)
+
+		if err = CheckNetErrClosing(s.server.Serve(lis)); err != nil {
+			s.logger.Errorw("serving grpc server with error", "error", err)
+
+			errCh <- err
+			return
+		}
+	}()
+
+	return errCh
+}
+
+// Drain marks all the services as not serving and rejects the new calls with the Unavailable code,
+// so the clients move to the other servers while the pending calls are finished.
+func (s *GrpcServer) Drain() {
+	s.Server.Shutdown()
+	s.drainer.Drain()
+
+	s.logger.Info("🚰 GRPC server is draining.")
+}
+
+// Stop drains the server for the drain period and stops the server gracefully,
+// the server is stopped forcibly if the pending RPCs aren't finished in the shutdown timeout.
+func (s *GrpcServer) Stop() (err error) {
+	s.stopOnce.Do(func() {
+		s.Drain()
+
+		timeout := DefaultShutdownTimeout
+		if shutdown := s.conf.GetGrpc().Shutdown; shutdown != nil {
+			if shutdown.Timeout > 0 {
+				timeout = shutdown.Timeout
+			}
+
+			if shutdown.DrainPeriod > 0 {
+				time.Sleep(shutdown.DrainPeriod)
+			}
+		}
+
+		stopped := make(chan struct{})
+		go func() {
+			s.server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(timeout):
+			s.server.Stop()
+			err = fmt.Errorf("grpc server graceful stop exceeded the timeout %s", timeout)
+		}
+
+		s.logger.Info("🛑 GRPC server stopped.")
+	})
+
+	return err
+}
+
+func (s *GrpcServer) setAllServingStatus(status pb.HealthCheckResponse_ServingStatus) {
+	s.SetServingStatus("", status)
+
+	for service := range s.server.GetServiceInfo() {
+		s.SetServingStatus(service, status)
+	}
+}
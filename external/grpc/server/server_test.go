@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	health "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+type testConfigs struct {
+	base *configs.Base
+	grpc *configs.GRPC
+}
+
+func (c *testConfigs) GetBase() *configs.Base     { return c.base }
+func (c *testConfigs) GetGrpc() *configs.GRPC     { return c.grpc }
+func (c *testConfigs) GetClient() *configs.Client { return nil }
+
+func freePort(t *testing.T) uint16 {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	return uint16(lis.Addr().(*net.TCPAddr).Port)
+}
+
+func TestGrpcServer(t *testing.T) {
+	conf := &testConfigs{
+		base: &configs.Base{},
+		grpc: &configs.GRPC{
+			Enabled:       true,
+			UseReflection: true,
+			Conn: &configs.Connection{
+				Host:     "127.0.0.1",
+				Port:     freePort(t),
+				Insecure: true,
+			},
+			Shutdown: &configs.Shutdown{Timeout: time.Second},
+		},
+	}
+
+	srv, err := NewGrpcServer(
+		configs.SetGrpcServerConfigs(conf),
+		configs.RegisterGrpcServices(func(s *grpc.Server) {
+			pb.RegisterGatewaySaverServiceServer(s, &pb.UnimplementedGatewaySaverServiceServer{})
+		}),
+	)
+	require.NoError(t, err)
+
+	errCh := srv.Start()
+
+	conn, err := grpc.Dial(net.JoinHostPort(conf.grpc.Conn.Host, fmt.Sprint(conf.grpc.Conn.Port)), grpc.WithInsecure(), grpc.WithBlock())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := health.NewHealthClient(conn)
+
+	var cases = []struct {
+		name    string
+		service string
+		want    health.HealthCheckResponse_ServingStatus
+		code    codes.Code
+	}{
+		{
+			name: "overall status",
+			want: health.HealthCheckResponse_SERVING,
+		},
+		{
+			name:    "registered service",
+			service: "services.GatewaySaverService",
+			want:    health.HealthCheckResponse_SERVING,
+		},
+		{
+			name:    "reflection service",
+			service: "grpc.reflection.v1alpha.ServerReflection",
+			want:    health.HealthCheckResponse_SERVING,
+		},
+		{
+			name:    "not registered service",
+			service: "services.Missing",
+			code:    codes.NotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			resp, err := client.Check(ctx, &health.HealthCheckRequest{Service: c.service})
+			if c.code != codes.OK {
+				assert.Equal(t, c.code, status.Code(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.want, resp.Status)
+		})
+	}
+
+	assert.NoError(t, srv.Stop())
+	assert.NoError(t, <-errCh)
+}
+
+func TestGrpcServerDrain(t *testing.T) {
+	conf := &testConfigs{
+		base: &configs.Base{},
+		grpc: &configs.GRPC{
+			Enabled: true,
+			Conn: &configs.Connection{
+				Host:     "127.0.0.1",
+				Port:     freePort(t),
+				Insecure: true,
+			},
+			Keepalive: &configs.Keepalive{
+				Time:             time.Minute,
+				Timeout:          time.Second,
+				MaxConnectionAge: time.Hour,
+			},
+			Shutdown: &configs.Shutdown{Timeout: time.Second, DrainPeriod: 10 * time.Millisecond},
+		},
+	}
+
+	var intercepted int32
+
+	srv, err := NewGrpcServer(
+		configs.SetGrpcServerConfigs(conf),
+		configs.RegisterGrpcServices(func(s *grpc.Server) {
+			pb.RegisterGatewaySaverServiceServer(s, &pb.UnimplementedGatewaySaverServiceServer{})
+		}),
+		configs.SetGrpcServerInterceptors(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			atomic.AddInt32(&intercepted, 1)
+			return handler(ctx, req)
+		}),
+	)
+	require.NoError(t, err)
+
+	errCh := srv.Start()
+
+	conn, err := grpc.Dial(net.JoinHostPort(conf.grpc.Conn.Host, fmt.Sprint(conf.grpc.Conn.Port)), grpc.WithInsecure(), grpc.WithBlock())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := pb.NewGatewaySaverServiceClient(conn)
+
+	_, err = client.GetMetricsOffset(ctx, &pb.ReqGetMetricsOffset{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	srv.Drain()
+
+	resp, err := health.NewHealthClient(conn).Check(ctx, &health.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, health.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	atomic.StoreInt32(&intercepted, 0)
+
+	_, err = client.GetMetricsOffset(ctx, &pb.ReqGetMetricsOffset{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// the rejected calls don't reach the other interceptors
+	assert.Zero(t, atomic.LoadInt32(&intercepted))
+
+	assert.NoError(t, srv.Stop())
+	assert.NoError(t, <-errCh)
+}
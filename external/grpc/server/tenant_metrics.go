@@ -0,0 +1,389 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+const (
+	// OtherLabel is the label value of the cluster ids and the client names cut by the cardinality guard.
+	OtherLabel = "other"
+
+	DefaultTenantLabelLimit = 100
+
+	// guardCountsFactor limits the number of the counted label values to the multiple of the guard limit.
+	guardCountsFactor = 10
+)
+
+var (
+	DefaultTenantBuckets     = prometheus.DefBuckets
+	DefaultTenantSizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+
+	tenantMetrics             *TenantMetrics
+	registerTenantMetricsOnce sync.Once
+)
+
+// TenantMetrics exports the request counts, the handling latency and the message sizes of the server calls
+// labelled by the cluster id and the client name of the call metadata.
+type TenantMetrics struct {
+	requests *prometheus.CounterVec
+	handling *prometheus.HistogramVec
+	received *prometheus.HistogramVec
+	sent     *prometheus.HistogramVec
+
+	// the calls of the admitted label values and the known series hold the read lock only,
+	// the new values and series and the evictions hold the write lock
+	mu       sync.RWMutex
+	clusters *labelGuard
+	clients  *labelGuard
+
+	// series holds the request counts of the series, they are indexed by the label values of the guards
+	series    map[tenantSeries]*uint64
+	byCluster map[string]map[tenantSeries]struct{}
+	byClient  map[string]map[tenantSeries]struct{}
+}
+
+type tenantSeries struct {
+	method, code, cluster, client string
+}
+
+// NewTenantMetrics returns the metrics collector, it has to be registered by the caller.
+func NewTenantMetrics(conf *configs.TenantMetrics) *TenantMetrics {
+	if conf == nil {
+		conf = &configs.TenantMetrics{}
+	}
+
+	buckets := conf.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultTenantBuckets
+	}
+
+	sizeBuckets := conf.SizeBuckets
+	if len(sizeBuckets) == 0 {
+		sizeBuckets = DefaultTenantSizeBuckets
+	}
+
+	labels := []string{"grpc_method", "cluster_id", "client_name"}
+
+	return &TenantMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_tenant_handled_total",
+			Help: "Total number of RPCs completed on the server by method, code, cluster id and client name.",
+		}, []string{"grpc_method", "grpc_code", "cluster_id", "client_name"}),
+		handling: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_tenant_handling_seconds",
+			Help:    "Histogram of response latency of RPCs handled by the server by method, cluster id and client name.",
+			Buckets: buckets,
+		}, labels),
+		received: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_tenant_msg_received_bytes",
+			Help:    "Histogram of the received message sizes of RPCs by method, cluster id and client name.",
+			Buckets: sizeBuckets,
+		}, labels),
+		sent: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_tenant_msg_sent_bytes",
+			Help:    "Histogram of the sent message sizes of RPCs by method, cluster id and client name.",
+			Buckets: sizeBuckets,
+		}, labels),
+		clusters:  newLabelGuard(conf.Clusters, conf.MaxClusters),
+		clients:   newLabelGuard(conf.Clients, conf.MaxClients),
+		series:    make(map[tenantSeries]*uint64),
+		byCluster: make(map[string]map[tenantSeries]struct{}),
+		byClient:  make(map[string]map[tenantSeries]struct{}),
+	}
+}
+
+// DefaultTenantMetrics returns the metrics registered in the default prometheus registry, they are created
+// by the configs of the first call only and the configs of the later calls are ignored, use NewTenantMetrics
+// with the own registry for the different configs.
+func DefaultTenantMetrics(conf *configs.TenantMetrics) *TenantMetrics {
+	registerTenantMetricsOnce.Do(func() {
+		tenantMetrics = NewTenantMetrics(conf)
+		prometheus.MustRegister(tenantMetrics)
+	})
+
+	return tenantMetrics
+}
+
+func (m *TenantMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.handling.Describe(ch)
+	m.received.Describe(ch)
+	m.sent.Describe(ch)
+}
+
+func (m *TenantMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.handling.Collect(ch)
+	m.received.Collect(ch)
+	m.sent.Collect(ch)
+}
+
+// Observe records the finished call. The request counts of the label values evicted by the guard
+// are folded into the "other" series, so the totals of the method are kept, the "other" counters grow
+// by the folded counts at once. The latency and the size histograms of the evicted values are deleted.
+func (m *TenantMetrics) Observe(_ context.Context, o *Observation) {
+	code := o.Code()
+
+	m.mu.RLock()
+	if s, ok := m.knownSeries(o, code); ok {
+		m.observe(s, o)
+		m.mu.RUnlock()
+
+		return
+	}
+	m.mu.RUnlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cluster, evictedCluster := m.clusters.value(o.ClusterID)
+	if len(evictedCluster) > 0 {
+		m.foldSeries(m.byCluster[evictedCluster], func(s tenantSeries) tenantSeries {
+			s.cluster = OtherLabel
+			return s
+		})
+	}
+
+	client, evictedClient := m.clients.value(o.ClientName)
+	if len(evictedClient) > 0 {
+		m.foldSeries(m.byClient[evictedClient], func(s tenantSeries) tenantSeries {
+			s.client = OtherLabel
+			return s
+		})
+	}
+
+	s := tenantSeries{method: o.FullMethod, code: code, cluster: cluster, client: client}
+	m.addSeries(s, 1)
+	m.observe(s, o)
+}
+
+// knownSeries counts the call of the admitted label values in its existing series, it holds the read lock.
+func (m *TenantMetrics) knownSeries(o *Observation, code string) (s tenantSeries, ok bool) {
+	cluster, clusterCount, ok := m.clusters.label(o.ClusterID)
+	if !ok {
+		return s, false
+	}
+
+	client, clientCount, ok := m.clients.label(o.ClientName)
+	if !ok {
+		return s, false
+	}
+
+	s = tenantSeries{method: o.FullMethod, code: code, cluster: cluster, client: client}
+
+	count, ok := m.series[s]
+	if !ok {
+		return s, false
+	}
+
+	for _, c := range []*uint64{clusterCount, clientCount, count} {
+		if c != nil {
+			atomic.AddUint64(c, 1)
+		}
+	}
+
+	return s, true
+}
+
+func (m *TenantMetrics) observe(s tenantSeries, o *Observation) {
+	m.requests.WithLabelValues(s.method, s.code, s.cluster, s.client).Inc()
+	m.handling.WithLabelValues(s.method, s.cluster, s.client).Observe(o.Duration.Seconds())
+	m.received.WithLabelValues(s.method, s.cluster, s.client).Observe(float64(o.ReceivedBytes))
+	m.sent.WithLabelValues(s.method, s.cluster, s.client).Observe(float64(o.SentBytes))
+}
+
+func (m *TenantMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return ObserverUnaryServerInterceptor(m)
+}
+
+func (m *TenantMetrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return ObserverStreamServerInterceptor(m)
+}
+
+func (m *TenantMetrics) addSeries(s tenantSeries, count uint64) {
+	if c, ok := m.series[s]; ok {
+		atomic.AddUint64(c, count)
+		return
+	}
+
+	m.series[s] = &count
+
+	if m.byCluster[s.cluster] == nil {
+		m.byCluster[s.cluster] = make(map[tenantSeries]struct{})
+	}
+
+	if m.byClient[s.client] == nil {
+		m.byClient[s.client] = make(map[tenantSeries]struct{})
+	}
+
+	m.byCluster[s.cluster][s] = struct{}{}
+	m.byClient[s.client][s] = struct{}{}
+}
+
+func (m *TenantMetrics) removeSeries(s tenantSeries) (count uint64) {
+	if c, ok := m.series[s]; ok {
+		count = atomic.LoadUint64(c)
+	}
+
+	delete(m.series, s)
+	delete(m.byCluster[s.cluster], s)
+	delete(m.byClient[s.client], s)
+
+	if len(m.byCluster[s.cluster]) == 0 {
+		delete(m.byCluster, s.cluster)
+	}
+
+	if len(m.byClient[s.client]) == 0 {
+		delete(m.byClient, s.client)
+	}
+
+	return count
+}
+
+// foldSeries adds the request counts of the series to the series returned by fold and deletes them.
+func (m *TenantMetrics) foldSeries(series map[tenantSeries]struct{}, fold func(s tenantSeries) tenantSeries) {
+	folded := make([]tenantSeries, 0, len(series))
+	for s := range series {
+		folded = append(folded, s)
+	}
+
+	for _, s := range folded {
+		count := m.removeSeries(s)
+
+		m.requests.DeleteLabelValues(s.method, s.code, s.cluster, s.client)
+		m.handling.DeleteLabelValues(s.method, s.cluster, s.client)
+		m.received.DeleteLabelValues(s.method, s.cluster, s.client)
+		m.sent.DeleteLabelValues(s.method, s.cluster, s.client)
+
+		other := fold(s)
+		m.addSeries(other, count)
+		m.requests.WithLabelValues(other.method, other.code, other.cluster, other.client).Add(float64(count))
+	}
+}
+
+// labelGuard bounds the number of the label values, only the allowed values pass when the allowlist is set,
+// otherwise the limit most frequent values pass and a value evicts the least frequent one once it is more frequent.
+type labelGuard struct {
+	allowed map[string]bool
+	limit   int
+
+	// admitted holds the counts of the admitted values, they grow under the read lock of the metrics
+	admitted map[string]*uint64
+	// counts holds the counts of the other values
+	counts map[string]uint64
+	// minCount is the lower bound of the admitted counts, the least frequent value is searched only by the values
+	// which are more frequent, as the admitted counts only grow
+	minCount uint64
+}
+
+func newLabelGuard(allowlist []string, limit int) *labelGuard {
+	g := &labelGuard{
+		limit:    limit,
+		admitted: make(map[string]*uint64),
+		counts:   make(map[string]uint64),
+	}
+
+	if len(allowlist) > 0 {
+		g.allowed = make(map[string]bool, len(allowlist))
+		for _, value := range allowlist {
+			g.allowed[value] = true
+		}
+	} else if g.limit <= 0 {
+		g.limit = DefaultTenantLabelLimit
+	}
+
+	return g
+}
+
+// label returns the label of the value without changing the guard, the count is set for the admitted values.
+// The values which aren't admitted yet have to be passed to the value method.
+func (g *labelGuard) label(v string) (label string, count *uint64, ok bool) {
+	if len(v) == 0 {
+		return v, nil, true
+	}
+
+	if g.allowed != nil {
+		if g.allowed[v] {
+			return v, nil, true
+		}
+
+		return OtherLabel, nil, true
+	}
+
+	if count, ok = g.admitted[v]; ok {
+		return v, count, true
+	}
+
+	return "", nil, false
+}
+
+func (g *labelGuard) value(v string) (label, evicted string) {
+	if label, count, ok := g.label(v); ok {
+		if count != nil {
+			atomic.AddUint64(count, 1)
+		}
+
+		return label, ""
+	}
+
+	g.counts[v]++
+
+	if len(g.admitted) < g.limit {
+		g.admit(v)
+		return v, ""
+	}
+
+	if g.counts[v] <= g.minCount {
+		g.trim()
+		return OtherLabel, ""
+	}
+
+	var (
+		least      string
+		leastCount uint64
+	)
+
+	for admitted, count := range g.admitted {
+		if c := atomic.LoadUint64(count); len(least) == 0 || c < leastCount {
+			least, leastCount = admitted, c
+		}
+	}
+
+	g.minCount = leastCount
+
+	if g.counts[v] <= leastCount {
+		g.trim()
+		return OtherLabel, ""
+	}
+
+	delete(g.admitted, least)
+	g.counts[least] = leastCount
+	g.admit(v)
+
+	return v, least
+}
+
+func (g *labelGuard) admit(v string) {
+	count := g.counts[v]
+
+	delete(g.counts, v)
+	g.admitted[v] = &count
+}
+
+// trim forgets the counts of the not admitted values when there are too many of them.
+func (g *labelGuard) trim() {
+	if len(g.counts) <= g.limit*guardCountsFactor {
+		return
+	}
+
+	for value := range g.counts {
+		delete(g.counts, value)
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+func TestLabelGuard(t *testing.T) {
+	var cases = []struct {
+		name      string
+		allowlist []string
+		limit     int
+		values    []string
+		want      []string
+	}{
+		{
+			name:      "allowlist",
+			allowlist: []string{"bsc-1"},
+			values:    []string{"bsc-1", "eth-1", ""},
+			want:      []string{"bsc-1", OtherLabel, ""},
+		},
+		{
+			name:   "top n",
+			limit:  2,
+			values: []string{"bsc-1", "eth-1", "sol-1", "bsc-1", "sol-1", "sol-1", "eth-1"},
+			want:   []string{"bsc-1", "eth-1", OtherLabel, "bsc-1", "sol-1", "sol-1", OtherLabel},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := newLabelGuard(c.allowlist, c.limit)
+
+			for i, value := range c.values {
+				label, _ := g.value(value)
+				assert.Equal(t, c.want[i], label, "value %d", i)
+			}
+		})
+	}
+}
+
+func TestTenantMetrics(t *testing.T) {
+	m := NewTenantMetrics(&configs.TenantMetrics{MaxClusters: 1})
+
+	observe := func(cluster string, err error) {
+		m.Observe(context.Background(), &Observation{
+			FullMethod: "/services.GatewaySaverService/GetMetricsOffset",
+			ClusterID:  cluster,
+			ClientName: "saver",
+			Err:        err,
+		})
+	}
+
+	observe("bsc-1", nil)
+	observe("bsc-1", status.Error(codes.NotFound, "not found"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(
+		"/services.GatewaySaverService/GetMetricsOffset", codes.NotFound.String(), "bsc-1", "saver")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.handling))
+
+	// eth-1 becomes more frequent than bsc-1 and takes over its series
+	observe("eth-1", nil)
+	observe("eth-1", nil)
+	observe("eth-1", nil)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(
+		"/services.GatewaySaverService/GetMetricsOffset", codes.OK.String(), "eth-1", "saver")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.handling))
+
+	// the counts of bsc-1 are folded into the other series with the two earlier calls of eth-1
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.requests.WithLabelValues(
+		"/services.GatewaySaverService/GetMetricsOffset", codes.OK.String(), OtherLabel, "saver")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(
+		"/services.GatewaySaverService/GetMetricsOffset", codes.NotFound.String(), OtherLabel, "saver")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.requests))
+	assert.NotContains(t, m.byCluster, "bsc-1")
+}
+
+func TestTenantMetricsConcurrentCalls(t *testing.T) {
+	m := NewTenantMetrics(&configs.TenantMetrics{MaxClusters: 3, MaxClients: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 500; j++ {
+				m.Observe(context.Background(), &Observation{
+					FullMethod: "/services.GatewaySaverService/GetMetricsOffset",
+					ClusterID:  fmt.Sprintf("cluster-%d", (i+j)%5),
+					ClientName: fmt.Sprintf("client-%d", j%3),
+				})
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	// the evictions fold the counts, but don't lose any of them
+	var total uint64
+	for _, count := range m.series {
+		total += *count
+	}
+
+	assert.Equal(t, uint64(8*500), total)
+	assert.LessOrEqual(t, len(m.byCluster), 4)
+	assert.LessOrEqual(t, len(m.byClient), 3)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"github.com/dysnix/predictkube-libs/external/tracing"
+)
+
+// TracingUnaryServerInterceptor continues the trace propagated by the client with the server span of the call.
+func TracingUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		ctx, span := tracing.StartGrpcServerSpan(ctx, info.FullMethod, peerAddr(ctx))
+		defer func() {
+			tracing.EndGrpcSpan(span, err)
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func TracingStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, span := tracing.StartGrpcServerSpan(ss.Context(), info.FullMethod, peerAddr(ss.Context()))
+		defer func() {
+			tracing.EndGrpcSpan(span, err)
+		}()
+
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func peerAddr(ctx context.Context) net.Addr {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Validator is implemented by the request messages validating themselves.
+type Validator interface {
+	Validate() error
+}
+
+// Rules are the validation tags of the message fields by the field path
+// in proto names, e.g. "header.cluster_id": "required,uuid_if_not_empty".
+type Rules map[string]string
+
+// RequestValidator validates the requests with the rule sets registered per message type,
+// the rules are checked by the validator instance with the custom tags of RegisterCustomValidationsTags.
+type RequestValidator struct {
+	validate *validator.Validate
+	rules    sync.Map
+}
+
+func NewRequestValidator(validate *validator.Validate) *RequestValidator {
+	return &RequestValidator{validate: validate}
+}
+
+// RegisterRules sets the rule set of the message type, the field paths are checked at registration.
+func (v *RequestValidator) RegisterRules(msg proto.Message, rules Rules) error {
+	desc := msg.ProtoReflect().Descriptor()
+
+	for path := range rules {
+		if _, err := fieldPath(desc, path); err != nil {
+			return err
+		}
+	}
+
+	v.rules.Store(desc.FullName(), rules)
+
+	return nil
+}
+
+// Validate checks the request with its Validate method and the registered rule set,
+// the violations are returned as the InvalidArgument status with the BadRequest details.
+func (v *RequestValidator) Validate(req interface{}) error {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	if r, ok := req.(Validator); ok {
+		if err := r.Validate(); err != nil {
+			violations = append(violations, errViolations(err)...)
+		}
+	}
+
+	if msg, ok := req.(proto.Message); ok && v != nil {
+		if rules, ok := v.rules.Load(msg.ProtoReflect().Descriptor().FullName()); ok {
+			violations = append(violations, v.ruleViolations(msg.ProtoReflect(), rules.(Rules))...)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	st, err := status.New(codes.InvalidArgument, "invalid request").
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	return st.Err()
+}
+
+func (v *RequestValidator) ruleViolations(msg protoreflect.Message, rules Rules) (out []*errdetails.BadRequest_FieldViolation) {
+	for path, tag := range rules {
+		value, ok := fieldValue(msg, path)
+		if !ok {
+			continue
+		}
+
+		if err := v.validate.Var(value, tag); err != nil {
+			var errs validator.ValidationErrors
+			if errors.As(err, &errs) {
+				for _, e := range errs {
+					out = append(out, &errdetails.BadRequest_FieldViolation{
+						Field:       path,
+						Description: fmt.Sprintf("failed on the %q rule", e.Tag()),
+					})
+				}
+
+				continue
+			}
+
+			out = append(out, &errdetails.BadRequest_FieldViolation{Field: path, Description: err.Error()})
+		}
+	}
+
+	return out
+}
+
+// errViolations converts the error of the Validate method, the validator errors
+// are split by the fields and the others are returned as a single violation.
+func errViolations(err error) (out []*errdetails.BadRequest_FieldViolation) {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []*errdetails.BadRequest_FieldViolation{{Description: err.Error()}}
+	}
+
+	for _, e := range errs {
+		field := e.Namespace()
+		// drop the name of the validated struct
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+
+		out = append(out, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: fmt.Sprintf("failed on the %q rule", e.Tag()),
+		})
+	}
+
+	return out
+}
+
+func fieldPath(desc protoreflect.MessageDescriptor, path string) (fields []protoreflect.FieldDescriptor, err error) {
+	names := strings.Split(path, ".")
+
+	for i, name := range names {
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, fmt.Errorf("message %s has no field %q", desc.FullName(), name)
+		}
+
+		fields = append(fields, fd)
+
+		if i < len(names)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return nil, fmt.Errorf("field %q of message %s is not a message", name, desc.FullName())
+			}
+
+			desc = fd.Message()
+		}
+	}
+
+	return fields, nil
+}
+
+// fieldValue returns the Go value of the field to validate, the unset messages are nil,
+// the lists and maps are returned as slices and maps to support the length rules and dive.
+// The fields of the unset parent messages are skipped, the rules of the parents check them.
+func fieldValue(msg protoreflect.Message, path string) (_ interface{}, ok bool) {
+	fields, _ := fieldPath(msg.Descriptor(), path)
+
+	for i, fd := range fields {
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() && !msg.Has(fd) {
+			return nil, i == len(fields)-1
+		}
+
+		value := msg.Get(fd)
+		if i < len(fields)-1 {
+			msg = value.Message()
+			continue
+		}
+
+		switch {
+		case fd.IsList():
+			list := value.List()
+			out := make([]interface{}, list.Len())
+			for j := range out {
+				out[j] = goValue(fd, list.Get(j))
+			}
+
+			return out, true
+		case fd.IsMap():
+			out := make(map[interface{}]interface{}, value.Map().Len())
+			value.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				out[k.Interface()] = goValue(fd.MapValue(), v)
+				return true
+			})
+
+			return out, true
+		default:
+			return goValue(fd, value), true
+		}
+	}
+
+	return nil, false
+}
+
+func goValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch {
+	case fd.Message() != nil:
+		return value.Message().Interface()
+	case fd.Enum() != nil:
+		return int32(value.Enum())
+	default:
+		return value.Interface()
+	}
+}
+
+// ValidationUnaryServerInterceptor rejects the invalid requests with the InvalidArgument code,
+// the validator may be nil when only the Validate methods of the messages are used.
+func ValidationUnaryServerInterceptor(v *RequestValidator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if err = v.Validate(req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamServerInterceptor validates every message received by the stream.
+func ValidationStreamServerInterceptor(v *RequestValidator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingServerStream{ServerStream: ss, validator: v})
+	}
+}
+
+type validatingServerStream struct {
+	grpc.ServerStream
+	validator *RequestValidator
+}
+
+func (s *validatingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.validator.Validate(m)
+}
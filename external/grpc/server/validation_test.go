@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-proto/external/proto/commonproto"
+	pb "github.com/dysnix/predictkube-proto/external/proto/services"
+)
+
+type selfValidatedReq struct {
+	*pb.ReqSendMetrics
+}
+
+func (r selfValidatedReq) Validate() error {
+	if r.GetMetricsOffset() == 0 {
+		return errors.New("metrics offset is required")
+	}
+
+	return nil
+}
+
+func TestRequestValidator(t *testing.T) {
+	validate := validator.New()
+	require.NoError(t, validate.RegisterValidation(configs.UUIDIfNotEmptyTag, configs.ValidateUUIDIfNotEmpty))
+
+	v := NewRequestValidator(validate)
+	require.NoError(t, v.RegisterRules(&pb.ReqSendMetrics{}, Rules{
+		"header":            "required",
+		"header.cluster_id": "uuid_if_not_empty",
+		"metric_values":     "min=1",
+	}))
+
+	assert.Error(t, v.RegisterRules(&pb.ReqSendMetrics{}, Rules{"header.unknown": "required"}))
+
+	metrics := []*commonproto.MetricValue{{}}
+
+	var cases = []struct {
+		name       string
+		req        interface{}
+		wantFields []string
+	}{
+		{
+			name: "valid request",
+			req:  &pb.ReqSendMetrics{Header: &pb.Header{ClusterId: uuid.NewString()}, MetricValues: metrics},
+		},
+		{
+			name:       "missing header and metrics",
+			req:        &pb.ReqSendMetrics{},
+			wantFields: []string{"header", "metric_values"},
+		},
+		{
+			name:       "custom tag violation",
+			req:        &pb.ReqSendMetrics{Header: &pb.Header{ClusterId: "bsc-1"}, MetricValues: metrics},
+			wantFields: []string{"header.cluster_id"},
+		},
+		{
+			name:       "validate method violation",
+			req:        selfValidatedReq{&pb.ReqSendMetrics{Header: &pb.Header{}, MetricValues: metrics}},
+			wantFields: []string{""},
+		},
+		{
+			name: "message without rules",
+			req:  &pb.Header{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := v.Validate(c.req)
+			if len(c.wantFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, codes.InvalidArgument, st.Code())
+			require.Len(t, st.Details(), 1)
+
+			badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+			require.True(t, ok)
+
+			var fields []string
+			for _, violation := range badRequest.GetFieldViolations() {
+				fields = append(fields, violation.GetField())
+			}
+
+			assert.ElementsMatch(t, c.wantFields, fields)
+		})
+	}
+}
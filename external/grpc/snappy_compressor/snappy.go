@@ -0,0 +1,97 @@
+// Package snappy_compressor is a wrapper for using github.com/klauspost/compress/snappy
+// with gRPC.
+package snappy_compressor
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
+)
+
+const Name = "snappy"
+
+type compressor struct {
+	// current holds *settings, it is replaced by Configure
+	current atomic.Value
+}
+
+type settings struct {
+	maxSize uint64
+
+	writers sync.Pool
+	readers sync.Pool
+}
+
+func init() {
+	c := &compressor{}
+	c.current.Store(&settings{maxSize: grpcC.DefaultMaxDecompressedSize})
+
+	encoding.RegisterCompressor(c)
+}
+
+// Configure updates the registered compressor with the decompressed size limit
+// of the compression config, snappy has no compression levels.
+func Configure(conf *configs.Compression) error {
+	encoding.GetCompressor(Name).(*compressor).current.Store(&settings{maxSize: grpcC.MaxDecompressedSize(conf)})
+
+	return nil
+}
+
+func (c *compressor) load() *settings {
+	return c.current.Load().(*settings)
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	s := c.load()
+
+	sw, ok := s.writers.Get().(*snappy.Writer)
+	if !ok {
+		sw = snappy.NewBufferedWriter(w)
+	} else {
+		sw.Reset(w)
+	}
+
+	return &snappyWriteCloser{Writer: sw, pool: &s.writers}, nil
+}
+
+type snappyWriteCloser struct {
+	*snappy.Writer
+	pool *sync.Pool
+}
+
+func (z *snappyWriteCloser) Close() error {
+	err := z.Writer.Close()
+
+	// release the destination writer before the snappy writer is pooled
+	z.Writer.Reset(nil)
+	z.pool.Put(z.Writer)
+
+	return err
+}
+
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	s := c.load()
+
+	sr, ok := s.readers.Get().(*snappy.Reader)
+	if !ok {
+		sr = snappy.NewReader(r)
+	} else {
+		sr.Reset(r)
+	}
+
+	return grpcC.NewLimitedReader(sr, s.maxSize, func() {
+		// release the source reader before the snappy reader is pooled
+		sr.Reset(nil)
+		s.readers.Put(sr)
+	}), nil
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
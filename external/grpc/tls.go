@@ -0,0 +1,223 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+const (
+	DefaultTLSReloadInterval = time.Minute
+)
+
+// NewServerTLSConfig returns the server TLS configuration which re-reads
+// the rotated certificate and CA files without the server restart.
+func NewServerTLSConfig(conf *configs.TLS) (*tls.Config, error) {
+	if len(conf.CertFile) == 0 || len(conf.KeyFile) == 0 {
+		return nil, errors.New("server TLS requires the certificate and key files")
+	}
+
+	minVersion, err := configs.ParseTLSVersion(conf.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	reloader, err := newCertReloader(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion: minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := reloader.current()
+
+			return &tls.Config{
+				MinVersion:   minVersion,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    pool,
+				ClientAuth:   conf.ClientAuth.AdaptToTLS(),
+			}, nil
+		},
+	}, nil
+}
+
+// NewClientTLSConfig returns the client TLS configuration, the server certificate is verified
+// with the system roots unless the CA file is set, the client certificate is optional (for mTLS).
+func NewClientTLSConfig(conf *configs.TLS) (*tls.Config, error) {
+	minVersion, err := configs.ParseTLSVersion(conf.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &tls.Config{
+		MinVersion: minVersion,
+		ServerName: conf.ServerName,
+	}
+
+	if len(conf.CertFile) == 0 && len(conf.CAFile) == 0 {
+		return out, nil
+	}
+
+	reloader, err := newCertReloader(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(conf.CertFile) > 0 {
+		out.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := reloader.current()
+			return cert, nil
+		}
+	}
+
+	if len(conf.CAFile) > 0 {
+		// the roots are verified by VerifyConnection for the CA file rotation support
+		// nolint:gosec
+		out.InsecureSkipVerify = true
+		out.VerifyConnection = func(cs tls.ConnectionState) error {
+			_, pool := reloader.current()
+			return verifyPeer(cs, pool)
+		}
+	}
+
+	return out, nil
+}
+
+func verifyPeer(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls: server didn't provide a certificate")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+
+	return err
+}
+
+type certReloader struct {
+	conf     *configs.TLS
+	interval time.Duration
+
+	mu       sync.RWMutex
+	checked  time.Time
+	modTimes map[string]time.Time
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+}
+
+func newCertReloader(conf *configs.TLS) (*certReloader, error) {
+	r := &certReloader{
+		conf:     conf,
+		interval: conf.ReloadInterval,
+		modTimes: make(map[string]time.Time),
+	}
+
+	if r.interval <= 0 {
+		r.interval = DefaultTLSReloadInterval
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// current returns the loaded certificate and CA pool, the files are checked for changes
+// at most once per reload interval and the previous ones are kept if the new files are broken.
+func (r *certReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.RLock()
+	cert, pool, checked := r.cert, r.pool, r.checked
+	r.mu.RUnlock()
+
+	if time.Since(checked) < r.interval {
+		return cert, pool
+	}
+
+	_ = r.load()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, r.pool
+}
+
+func (r *certReloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checked = time.Now()
+
+	changed := r.cert == nil && r.pool == nil
+	for _, file := range []string{r.conf.CertFile, r.conf.KeyFile, r.conf.CAFile} {
+		if len(file) == 0 {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	var (
+		cert *tls.Certificate
+		pool *x509.CertPool
+	)
+
+	if len(r.conf.CertFile) > 0 {
+		pair, err := tls.LoadX509KeyPair(r.conf.CertFile, r.conf.KeyFile)
+		if err != nil {
+			return err
+		}
+
+		cert = &pair
+	}
+
+	if len(r.conf.CAFile) > 0 {
+		pem, err := ioutil.ReadFile(r.conf.CAFile)
+		if err != nil {
+			return err
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no CA certificates found in %q", r.conf.CAFile)
+		}
+	}
+
+	for _, file := range []string{r.conf.CertFile, r.conf.KeyFile, r.conf.CAFile} {
+		if info, err := os.Stat(file); err == nil {
+			r.modTimes[file] = info.ModTime()
+		}
+	}
+
+	r.cert, r.pool = cert, pool
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package grpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+)
+
+func TestTLSConfigReload(t *testing.T) {
+	dir := t.TempDir()
+
+	caCert, caKey := newTestCert(t, nil, nil, "test-ca", 1)
+	writeTestPEM(t, filepath.Join(dir, "ca.pem"), caCert, nil)
+
+	srvCert, srvKey := newTestCert(t, caCert, caKey, "localhost", 2)
+	writeTestPEM(t, filepath.Join(dir, "server.pem"), srvCert, srvKey)
+
+	cliCert, cliKey := newTestCert(t, caCert, caKey, "client", 3)
+	writeTestPEM(t, filepath.Join(dir, "client.pem"), cliCert, cliKey)
+
+	srvConf, err := NewServerTLSConfig(&configs.TLS{
+		CertFile:       filepath.Join(dir, "server.pem"),
+		KeyFile:        filepath.Join(dir, "server.pem.key"),
+		CAFile:         filepath.Join(dir, "ca.pem"),
+		ClientAuth:     enums.RequireAndVerifyClientCert,
+		ReloadInterval: time.Nanosecond,
+	})
+	require.NoError(t, err)
+
+	var cases = []struct {
+		name       string
+		clientConf *configs.TLS
+		rotate     bool
+		wantSerial int64
+		wantErr    bool
+	}{
+		{
+			name: "mutual tls",
+			clientConf: &configs.TLS{
+				CertFile:   filepath.Join(dir, "client.pem"),
+				KeyFile:    filepath.Join(dir, "client.pem.key"),
+				CAFile:     filepath.Join(dir, "ca.pem"),
+				ServerName: "localhost",
+			},
+			wantSerial: 2,
+		},
+		{
+			name: "rotated server certificate",
+			clientConf: &configs.TLS{
+				CertFile:   filepath.Join(dir, "client.pem"),
+				KeyFile:    filepath.Join(dir, "client.pem.key"),
+				CAFile:     filepath.Join(dir, "ca.pem"),
+				ServerName: "localhost",
+			},
+			rotate:     true,
+			wantSerial: 4,
+		},
+		{
+			name: "missing client certificate",
+			clientConf: &configs.TLS{
+				CAFile:     filepath.Join(dir, "ca.pem"),
+				ServerName: "localhost",
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong server name",
+			clientConf: &configs.TLS{
+				CertFile:   filepath.Join(dir, "client.pem"),
+				KeyFile:    filepath.Join(dir, "client.pem.key"),
+				CAFile:     filepath.Join(dir, "ca.pem"),
+				ServerName: "example.com",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.rotate {
+				rotated, rotatedKey := newTestCert(t, caCert, caKey, "localhost", 4)
+				writeTestPEM(t, filepath.Join(dir, "server.pem"), rotated, rotatedKey)
+
+				future := time.Now().Add(time.Minute)
+				for _, file := range []string{"server.pem", "server.pem.key"} {
+					require.NoError(t, os.Chtimes(filepath.Join(dir, file), future, future))
+				}
+			}
+
+			cliConf, err := NewClientTLSConfig(c.clientConf)
+			require.NoError(t, err)
+
+			srvConn, cliConn := net.Pipe()
+			defer srvConn.Close()
+			defer cliConn.Close()
+
+			go func() {
+				_ = tls.Server(srvConn, srvConf).Handshake()
+				_ = srvConn.Close()
+			}()
+
+			client := tls.Client(cliConn, cliConf)
+			err = client.Handshake()
+			if err == nil {
+				// the server verifies the client certificate after the client handshake is finished
+				_, err = client.Read(make([]byte, 1))
+			}
+
+			if c.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.Len(t, client.ConnectionState().PeerCertificates, 1)
+			assert.Equal(t, c.wantSerial, client.ConnectionState().PeerCertificates[0].SerialNumber.Int64())
+		})
+	}
+}
+
+func newTestCert(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, name string, serial int64) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+func writeTestPEM(t *testing.T, path string, cert *x509.Certificate, key *ecdsa.PrivateKey) {
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600))
+
+	if key != nil {
+		der, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		require.NoError(t, ioutil.WriteFile(path+".key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+	}
+}
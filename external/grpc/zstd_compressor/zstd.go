@@ -3,81 +3,188 @@
 package zstd_compressor
 
 import (
-	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"sync"
+	"sync/atomic"
 
 	"github.com/klauspost/compress/zstd"
 	"google.golang.org/grpc/encoding"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	grpcC "github.com/dysnix/predictkube-libs/external/grpc"
 )
 
 const Name = "zstd"
 
 type compressor struct {
-	encoder *zstd.Encoder
-	decoder *zstd.Decoder
+	// current holds *settings, it is replaced by Configure and SetLevel
+	current atomic.Value
+}
+
+// settings are immutable, the pools are recreated with every settings change
+// so the encoders and decoders never mix the options.
+type settings struct {
+	level        zstd.EncoderLevel
+	maxSize      uint64
+	dictionaries [][]byte
+
+	encoders sync.Pool
+	decoders sync.Pool
 }
 
 func init() {
-	enc, _ := zstd.NewWriter(nil)
-	dec, _ := zstd.NewReader(nil)
-	c := &compressor{
-		encoder: enc,
-		decoder: dec,
-	}
+	c := &compressor{}
+	c.current.Store(newSettings(zstd.SpeedDefault, grpcC.DefaultMaxDecompressedSize, nil))
+
 	encoding.RegisterCompressor(c)
 }
 
-// SetLevel updates the registered compressor to use a particular compression
-// level. NOTE: this function must only be called from an init function, and
-// is not threadsafe.
+// Configure updates the registered compressor with the level, the decompressed size limit
+// and the dictionaries of the compression config, the first dictionary is used for
+// compression and all of them are accepted by decompression. It is safe to call it at any time.
+func Configure(conf *configs.Compression) (err error) {
+	level := zstd.SpeedDefault
+	if conf.Level > 0 {
+		level = zstd.EncoderLevelFromZstd(conf.Level)
+	}
+
+	dictionaries := make([][]byte, 0, len(conf.Dictionaries))
+	for _, path := range conf.Dictionaries {
+		dict, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read zstd dictionary %q: %w", path, err)
+		}
+
+		dictionaries = append(dictionaries, dict)
+	}
+
+	return update(newSettings(level, grpcC.MaxDecompressedSize(conf), dictionaries))
+}
+
+// SetLevel updates the registered compressor to use a particular compression level.
 func SetLevel(level zstd.EncoderLevel) error {
-	c := encoding.GetCompressor(Name).(*compressor)
+	old := registered().load()
 
-	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+	return update(newSettings(level, old.maxSize, old.dictionaries))
+}
+
+func registered() *compressor {
+	return encoding.GetCompressor(Name).(*compressor)
+}
+
+func update(s *settings) error {
+	// check the options before the settings are used by the calls
+	enc, err := s.newEncoder()
+	if err != nil {
+		return err
+	}
+
+	dec, err := s.newDecoder()
 	if err != nil {
 		return err
 	}
 
-	c.encoder = enc
+	s.encoders.Put(enc)
+	s.decoders.Put(dec)
+
+	registered().current.Store(s)
+
 	return nil
 }
 
-func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
-	return &zstdWriteCloser{
-		enc:    c.encoder,
-		writer: w,
-	}, nil
+func newSettings(level zstd.EncoderLevel, maxSize uint64, dictionaries [][]byte) *settings {
+	return &settings{
+		level:        level,
+		maxSize:      maxSize,
+		dictionaries: dictionaries,
+	}
 }
 
-type zstdWriteCloser struct {
-	enc    *zstd.Encoder
-	writer io.Writer    // Compressed data will be written here.
-	buf    bytes.Buffer // Buffer uncompressed data here, compress on Close.
+func (s *settings) newEncoder() (*zstd.Encoder, error) {
+	opts := []zstd.EOption{
+		zstd.WithEncoderLevel(s.level),
+		zstd.WithEncoderConcurrency(1),
+	}
+
+	if len(s.dictionaries) > 0 {
+		opts = append(opts, zstd.WithEncoderDict(s.dictionaries[0]))
+	}
+
+	return zstd.NewWriter(nil, opts...)
 }
 
-func (z *zstdWriteCloser) Write(p []byte) (int, error) {
-	return z.buf.Write(p)
+func (s *settings) newDecoder() (*zstd.Decoder, error) {
+	opts := []zstd.DOption{
+		// the single goroutine decoders don't leak when they are dropped by the pool
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderMaxMemory(s.maxSize),
+	}
+
+	if len(s.dictionaries) > 0 {
+		opts = append(opts, zstd.WithDecoderDicts(s.dictionaries...))
+	}
+
+	return zstd.NewReader(nil, opts...)
+}
+
+func (c *compressor) load() *settings {
+	return c.current.Load().(*settings)
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	s := c.load()
+
+	enc, ok := s.encoders.Get().(*zstd.Encoder)
+	if !ok {
+		var err error
+		if enc, err = s.newEncoder(); err != nil {
+			return nil, err
+		}
+	}
+
+	enc.Reset(w)
+
+	return &zstdWriteCloser{Encoder: enc, pool: &s.encoders}, nil
+}
+
+type zstdWriteCloser struct {
+	*zstd.Encoder
+	pool *sync.Pool
 }
 
 func (z *zstdWriteCloser) Close() error {
-	compressed := z.enc.EncodeAll(z.buf.Bytes(), nil)
-	_, err := io.Copy(z.writer, bytes.NewReader(compressed))
+	err := z.Encoder.Close()
+
+	// release the destination writer before the encoder is pooled
+	z.Encoder.Reset(nil)
+	z.pool.Put(z.Encoder)
+
 	return err
 }
 
 func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
-	compressed, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
+	s := c.load()
+
+	dec, ok := s.decoders.Get().(*zstd.Decoder)
+	if !ok {
+		var err error
+		if dec, err = s.newDecoder(); err != nil {
+			return nil, err
+		}
 	}
 
-	uncompressed, err := c.decoder.DecodeAll(compressed, nil)
-	if err != nil {
+	if err := dec.Reset(r); err != nil {
+		s.decoders.Put(dec)
 		return nil, err
 	}
 
-	return bytes.NewReader(uncompressed), nil
+	return grpcC.NewLimitedReader(dec, s.maxSize, func() {
+		// release the source reader before the decoder is pooled
+		_ = dec.Reset(nil)
+		s.decoders.Put(dec)
+	}), nil
 }
 
 func (c *compressor) Name() string {
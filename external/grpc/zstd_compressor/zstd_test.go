@@ -0,0 +1,111 @@
+package zstd_compressor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+func compress(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+
+	w, err := encoding.GetCompressor(Name).Compress(&buf)
+	require.NoError(t, err)
+
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestCompressor(t *testing.T) {
+	payload := []byte(strings.Repeat(`{"cluster_id":"bsc-1","metric":"cpu","value":0.42}`, 1000))
+
+	var cases = []struct {
+		name    string
+		conf    *configs.Compression
+		data    []byte
+		wantErr bool
+	}{
+		{
+			name: "default settings",
+			conf: &configs.Compression{},
+			data: payload,
+		},
+		{
+			name: "best compression level",
+			conf: &configs.Compression{Level: 19},
+			data: payload,
+		},
+		{
+			name: "empty message",
+			conf: &configs.Compression{},
+			data: []byte{},
+		},
+		{
+			name:    "decompressed size limit",
+			conf:    &configs.Compression{MaxDecompressedSize: 1024},
+			data:    payload,
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.NoError(t, Configure(c.conf))
+			defer func() {
+				require.NoError(t, Configure(&configs.Compression{}))
+			}()
+
+			compressed := compress(t, c.data)
+
+			r, err := encoding.GetCompressor(Name).Decompress(bytes.NewReader(compressed))
+			require.NoError(t, err)
+
+			out, err := ioutil.ReadAll(r)
+			if c.wantErr {
+				assert.Error(t, err)
+				assert.LessOrEqual(t, uint(len(out)), c.conf.MaxDecompressedSize)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.data, out)
+		})
+	}
+}
+
+func TestCompressorConcurrentConfigure(t *testing.T) {
+	payload := []byte(strings.Repeat("metric payload ", 100))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func(level int) {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				assert.NoError(t, Configure(&configs.Compression{Level: level}))
+
+				r, err := encoding.GetCompressor(Name).Decompress(bytes.NewReader(compress(t, payload)))
+				require.NoError(t, err)
+
+				out, err := ioutil.ReadAll(r)
+				assert.NoError(t, err)
+				assert.Equal(t, payload, out)
+			}
+		}(i%4 + 1)
+	}
+
+	wg.Wait()
+}
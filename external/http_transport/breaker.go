@@ -0,0 +1,47 @@
+package http_transport
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dysnix/predictkube-libs/external/breaker"
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+// breakerTransport wraps the http.RoundTripper with the circuit breakers of the request hosts.
+type breakerTransport struct {
+	next  http.RoundTripper
+	group *breaker.Group
+}
+
+// NewCircuitBreakerTransport returns the round tripper rejecting the requests with breaker.ErrOpen
+// while the breaker of the request host is open, the server errors and the 429 responses are failures.
+func NewCircuitBreakerTransport(next http.RoundTripper, conf *configs.CircuitBreaker) HttpTransport {
+	return &breakerTransport{next: next, group: breaker.NewGroup(conf)}
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.group.Get(req.URL.Host).Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	success := false
+
+	// the panicked round trips are counted as the failures
+	defer func() {
+		done(success, time.Since(start))
+	}()
+
+	res, err := t.next.RoundTrip(req)
+	success = err == nil && res.StatusCode < http.StatusInternalServerError && res.StatusCode != http.StatusTooManyRequests
+
+	return res, err
+}
+
+func (t *breakerTransport) Close() {
+	if closer, ok := t.next.(configs.SignalCloser); ok {
+		closer.Close()
+	}
+}
@@ -11,6 +11,7 @@ import (
 	tr "k8s.io/client-go/transport"
 
 	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/tracing"
 )
 
 // Transport implements the http.RoundTripper interface with
@@ -85,7 +86,14 @@ func NewTransport(opts *configs.HTTPTransport, tlsConf ...tr.TLSConfig) *transpo
 }
 
 // RoundTrip performs the request and returns a response or error
-func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+func (t *transport) RoundTrip(req *http.Request) (_ *http.Response, err error) {
+	req, span := tracing.StartClientSpan(req)
+
+	var res *http.Response
+	defer func() {
+		tracing.EndClientSpan(span, res, err)
+	}()
+
 	freq := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(freq)
 
@@ -94,12 +102,11 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	copyRequest(freq, req)
 
-	err := t.client.Do(freq, fres)
-	if err != nil {
+	if err = t.client.Do(freq, fres); err != nil {
 		return nil, err
 	}
 
-	res := &http.Response{Header: make(http.Header)}
+	res = &http.Response{Header: make(http.Header)}
 	copyResponse(res, fres)
 
 	return res, nil
@@ -0,0 +1,72 @@
+package http_transport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/fault"
+)
+
+const (
+	clusterIDHeader = "X-Auth-Cluster-Id"
+)
+
+// faultTransport wraps the http.RoundTripper with the faults of the injector.
+type faultTransport struct {
+	next     http.RoundTripper
+	injector *fault.Injector
+}
+
+// NewFaultTransport returns the round tripper injecting the faults into the requests by the request paths
+// and the cluster id header, the failed requests get the synthetic responses of the fault status
+// and the responses of the dropped requests are discarded until the request context is done.
+func NewFaultTransport(next http.RoundTripper, injector *fault.Injector) HttpTransport {
+	return &faultTransport{next: next, injector: injector}
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f := t.injector.Pick(fault.HTTPSide, req.URL.Path, req.Header.Get(clusterIDHeader))
+	if f == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	if err := f.Delay(ctx); err != nil {
+		return nil, err
+	}
+
+	if f.HTTPStatus != 0 {
+		body := http.StatusText(f.HTTPStatus)
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", f.HTTPStatus, body),
+			StatusCode:    f.HTTPStatus,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			Body:          io.NopCloser(bytes.NewBufferString(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil || !f.Drop {
+		return res, err
+	}
+
+	_ = res.Body.Close()
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+func (t *faultTransport) Close() {
+	if closer, ok := t.next.(configs.SignalCloser); ok {
+		closer.Close()
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	libs "github.com/dysnix/predictkube-libs/external/configs"
 	"github.com/dysnix/predictkube-libs/external/enums"
+	"github.com/dysnix/predictkube-libs/external/fault"
 )
 
 func RequestJob(ctx context.Context, remoteClient *http.Client, logger *zap.SugaredLogger, backendURL, method string, requestBody string, requestHeaders map[string]string) (respStatus int, compressed bool, dataClear *bytes.Buffer, err error) {
@@ -127,6 +128,19 @@ func InitHTTPClient(t enums.TransportType, conf *libs.HTTPTransport) (httpClient
 		}
 	}
 
+	if conf != nil && conf.FaultInjection != nil && conf.FaultInjection.Enabled {
+		injector, err := fault.For(conf.FaultInjection)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		roundTripper = NewFaultTransport(roundTripper, injector)
+	}
+
+	if conf != nil && conf.CircuitBreaker != nil && conf.CircuitBreaker.Enabled {
+		roundTripper = NewCircuitBreakerTransport(roundTripper, conf.CircuitBreaker)
+	}
+
 	return &http.Client{
 		Transport: roundTripper,
 		Timeout:   time.Second * 15,
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/tracing"
 )
 
 const (
@@ -112,8 +113,10 @@ func (t *netHttpTransport) RoundTrip(r *http.Request) (*http.Response, error) {
 		}
 	}
 
+	r, span := tracing.StartClientSpan(r)
 	resp, err := t.rtp.RoundTrip(r)
 	event.reqEnd = time.Now()
+	tracing.EndClientSpan(span, resp, err)
 
 	t.statsStore.Store(k, event)
 
@@ -0,0 +1,112 @@
+package limiter
+
+import (
+	"math"
+	"time"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+)
+
+const (
+	gradientSmoothing   = 0.2
+	gradientShortWeight = 0.1
+	gradientLongWeight  = 0.01
+	gradientMinRatio    = 0.5
+)
+
+// limit is the algorithm of the concurrency limit, it is updated by the samples of the finished calls.
+type limit interface {
+	Limit() float64
+	Update(rtt time.Duration, inflight int, success bool)
+}
+
+func newLimit(conf configs.ConcurrencyLimit) limit {
+	switch conf.Mode {
+	case enums.AIMD:
+		return &aimdLimit{conf: conf, limit: float64(conf.Limit)}
+	case enums.Gradient:
+		return &gradientLimit{conf: conf, limit: float64(conf.Limit)}
+	default:
+		return staticLimit(conf.Limit)
+	}
+}
+
+type staticLimit float64
+
+func (l staticLimit) Limit() float64 {
+	return float64(l)
+}
+
+func (l staticLimit) Update(time.Duration, int, bool) {}
+
+// aimdLimit grows the limit by one while the calls are fast and succeed and the limit is in use,
+// the failed calls and the calls slower than the latency threshold back the limit off.
+type aimdLimit struct {
+	conf  configs.ConcurrencyLimit
+	limit float64
+}
+
+func (l *aimdLimit) Limit() float64 {
+	return l.limit
+}
+
+func (l *aimdLimit) Update(rtt time.Duration, inflight int, success bool) {
+	switch {
+	case !success || (l.conf.LatencyThreshold > 0 && rtt > l.conf.LatencyThreshold):
+		l.limit = clamp(math.Floor(l.limit*l.conf.BackoffRatio), l.conf)
+	case float64(inflight)*2 >= l.limit:
+		l.limit = clamp(l.limit+1, l.conf)
+	}
+}
+
+// gradientLimit scales the limit by the ratio of the long-term average latency to the short-term one,
+// the limit grows by the square root of itself while the latency holds and shrinks once it grows
+// by more than the tolerance.
+type gradientLimit struct {
+	conf     configs.ConcurrencyLimit
+	limit    float64
+	shortRTT float64
+	longRTT  float64
+}
+
+func (l *gradientLimit) Limit() float64 {
+	return l.limit
+}
+
+func (l *gradientLimit) Update(rtt time.Duration, inflight int, success bool) {
+	sample := float64(rtt)
+	if l.longRTT == 0 {
+		l.shortRTT, l.longRTT = sample, sample
+	}
+
+	l.shortRTT += (sample - l.shortRTT) * gradientShortWeight
+	l.longRTT += (sample - l.longRTT) * gradientLongWeight
+
+	// the limit isn't grown while it isn't in use
+	if success && float64(inflight) < l.limit/2 {
+		return
+	}
+
+	// the latency below the clock resolution holds, the ratio of the zero latencies isn't a number
+	gradient := 1.0
+	if l.shortRTT > 0 {
+		gradient = math.Max(gradientMinRatio, math.Min(1, l.conf.Tolerance*l.longRTT/l.shortRTT))
+	}
+
+	if !success {
+		gradient = gradientMinRatio
+	}
+
+	next := l.limit*gradient + math.Sqrt(l.limit)
+	l.limit = clamp(l.limit*(1-gradientSmoothing)+next*gradientSmoothing, l.conf)
+}
+
+// clamp bounds the limit by the configs, the limit which isn't a number falls back to the minimum.
+func clamp(limit float64, conf configs.ConcurrencyLimit) float64 {
+	if math.IsNaN(limit) {
+		return float64(conf.MinLimit)
+	}
+
+	return math.Max(float64(conf.MinLimit), math.Min(float64(conf.MaxLimit), limit))
+}
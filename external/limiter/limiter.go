@@ -0,0 +1,147 @@
+// Package limiter implements the static and the adaptive concurrency limits of the server calls.
+package limiter
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+)
+
+const (
+	DefaultLimit        = 100
+	DefaultMinLimit     = 1
+	DefaultMaxLimit     = 1000
+	DefaultBackoffRatio = 0.9
+	DefaultTolerance    = 1.5
+
+	defaultClass = "default"
+)
+
+var ErrLimitExceeded = errors.New("concurrency limit exceeded")
+
+// Limiter rejects the calls once the in-flight calls reach the share of the limit of the call class.
+type Limiter struct {
+	name    string
+	classes []configs.PriorityClass
+
+	mu       sync.Mutex
+	limit    limit
+	inflight int
+}
+
+// New returns the limiter of the configs, the name is the label of its metrics.
+func New(name string, conf *configs.ConcurrencyLimit) *Limiter {
+	registerMetrics()
+
+	c := withDefaults(conf)
+
+	l := &Limiter{
+		name:    name,
+		classes: c.Classes,
+		limit:   newLimit(c),
+	}
+
+	limitGauge.WithLabelValues(name).Set(l.limit.Limit())
+	inflightGauge.WithLabelValues(name).Set(0)
+
+	return l
+}
+
+func withDefaults(conf *configs.ConcurrencyLimit) configs.ConcurrencyLimit {
+	var c configs.ConcurrencyLimit
+	if conf != nil {
+		c = *conf
+	}
+
+	if c.Limit == 0 {
+		c.Limit = DefaultLimit
+	}
+
+	if c.MinLimit == 0 {
+		c.MinLimit = DefaultMinLimit
+	}
+
+	if c.MaxLimit == 0 {
+		c.MaxLimit = DefaultMaxLimit
+	}
+
+	if c.MaxLimit < c.Limit {
+		c.MaxLimit = c.Limit
+	}
+
+	if c.BackoffRatio == 0 {
+		c.BackoffRatio = DefaultBackoffRatio
+	}
+
+	if c.Tolerance == 0 {
+		c.Tolerance = DefaultTolerance
+	}
+
+	return c
+}
+
+// Limit returns the current limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit.Limit())
+}
+
+// Inflight returns the number of the in-flight calls.
+func (l *Limiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inflight
+}
+
+// Acquire reserves the slot of the call of the full gRPC method name and the client name, it returns ErrLimitExceeded
+// once the in-flight calls fill the share of the limit of the call class. The done func releases the slot
+// and updates the adaptive limit by the outcome and the latency of the call.
+func (l *Limiter) Acquire(fullMethod, client string) (done func(success bool, rtt time.Duration), err error) {
+	class, share := l.classOf(fullMethod, client)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if float64(l.inflight) >= math.Max(1, math.Floor(l.limit.Limit()*share)) {
+		rejectedTotal.WithLabelValues(l.name, class).Inc()
+		return nil, ErrLimitExceeded
+	}
+
+	l.inflight++
+	inflightGauge.WithLabelValues(l.name).Set(float64(l.inflight))
+
+	var once sync.Once
+
+	return func(success bool, rtt time.Duration) {
+		once.Do(func() {
+			l.release(success, rtt)
+		})
+	}, nil
+}
+
+func (l *Limiter) release(success bool, rtt time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit.Update(rtt, l.inflight, success)
+	l.inflight--
+
+	inflightGauge.WithLabelValues(l.name).Set(float64(l.inflight))
+	limitGauge.WithLabelValues(l.name).Set(l.limit.Limit())
+}
+
+func (l *Limiter) classOf(fullMethod, client string) (name string, share float64) {
+	for i := range l.classes {
+		if l.classes[i].Matches(fullMethod, client) {
+			return l.classes[i].Name, l.classes[i].Share
+		}
+	}
+
+	return defaultClass, 1
+}
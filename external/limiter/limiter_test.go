@@ -0,0 +1,165 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+)
+
+func TestLimiterClasses(t *testing.T) {
+	l := New("classes", &configs.ConcurrencyLimit{
+		Enabled: true,
+		Limit:   4,
+		Classes: []configs.PriorityClass{
+			{Name: "batch", Methods: []string{"services.GatewaySaverService/SendMetrics"}, Share: 0.5},
+			{Name: "agent", Clients: []string{"agent"}, Share: 1},
+		},
+	})
+
+	var dones []func(bool, time.Duration)
+
+	acquire := func(method, client string) error {
+		done, err := l.Acquire(method, client)
+		if err == nil {
+			dones = append(dones, done)
+		}
+
+		return err
+	}
+
+	// the batch class fills only the half of the limit
+	require.NoError(t, acquire("/services.GatewaySaverService/SendMetrics", ""))
+	require.NoError(t, acquire("/services.GatewaySaverService/SendMetrics", "agent"))
+	assert.ErrorIs(t, acquire("/services.GatewaySaverService/SendMetrics", ""), ErrLimitExceeded)
+
+	// the other calls fill the whole limit
+	require.NoError(t, acquire("/services.GatewaySaverService/GetMetricsOffset", "agent"))
+	require.NoError(t, acquire("/services.GatewaySaverService/GetMetricsOffset", ""))
+	assert.ErrorIs(t, acquire("/services.GatewaySaverService/GetMetricsOffset", "agent"), ErrLimitExceeded)
+	assert.Equal(t, 4, l.Inflight())
+
+	for _, done := range dones {
+		done(true, time.Millisecond)
+		done(true, time.Millisecond)
+	}
+
+	assert.Equal(t, 0, l.Inflight())
+	assert.Equal(t, 4, l.Limit())
+}
+
+func TestAIMDLimit(t *testing.T) {
+	l := New("aimd", &configs.ConcurrencyLimit{
+		Enabled:          true,
+		Mode:             enums.AIMD,
+		Limit:            10,
+		MaxLimit:         11,
+		LatencyThreshold: 100 * time.Millisecond,
+		BackoffRatio:     0.5,
+	})
+
+	// the limit isn't grown while it isn't in use
+	done, err := l.Acquire("/services.GatewaySaverService/GetMetricsOffset", "")
+	require.NoError(t, err)
+	done(true, time.Millisecond)
+	assert.Equal(t, 10, l.Limit())
+
+	var dones []func(bool, time.Duration)
+	for i := 0; i < 6; i++ {
+		done, err = l.Acquire("/services.GatewaySaverService/GetMetricsOffset", "")
+		require.NoError(t, err)
+		dones = append(dones, done)
+	}
+
+	dones[0](true, time.Millisecond)
+	assert.Equal(t, 11, l.Limit())
+
+	dones[1](true, time.Millisecond)
+	assert.Equal(t, 11, l.Limit(), "the max limit")
+
+	dones[2](true, time.Second)
+	assert.Equal(t, 5, l.Limit(), "the slow call")
+
+	dones[3](false, time.Millisecond)
+	assert.Equal(t, 2, l.Limit(), "the failed call")
+}
+
+func TestGradientLimit(t *testing.T) {
+	l := New("gradient", &configs.ConcurrencyLimit{
+		Enabled:  true,
+		Mode:     enums.Gradient,
+		Limit:    4,
+		MaxLimit: 100,
+	})
+
+	run := func(rtt time.Duration) {
+		var dones []func(bool, time.Duration)
+		for i := 0; i < l.Limit(); i++ {
+			done, err := l.Acquire("/services.GatewaySaverService/GetMetricsOffset", "")
+			require.NoError(t, err)
+			dones = append(dones, done)
+		}
+
+		for _, done := range dones {
+			done(true, rtt)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		run(10 * time.Millisecond)
+	}
+
+	grown := l.Limit()
+	assert.Greater(t, grown, 4, "the limit grows while the latency holds")
+
+	for i := 0; i < 10; i++ {
+		run(100 * time.Millisecond)
+	}
+
+	assert.Less(t, l.Limit(), grown, "the limit shrinks once the latency grows")
+}
+
+func TestGradientLimitZeroRTT(t *testing.T) {
+	const method = "/services.GatewaySaverService/GetMetricsOffset"
+
+	l := New("gradient_zero_rtt", &configs.ConcurrencyLimit{
+		Enabled:  true,
+		Mode:     enums.Gradient,
+		Limit:    4,
+		MaxLimit: 8,
+	})
+
+	// the calls faster than the clock resolution
+	for i := 0; i < 20; i++ {
+		var dones []func(bool, time.Duration)
+		for j := 0; j < l.Limit(); j++ {
+			done, err := l.Acquire(method, "")
+			require.NoError(t, err)
+			dones = append(dones, done)
+		}
+
+		for _, done := range dones {
+			done(true, 0)
+		}
+	}
+
+	assert.Equal(t, 8, l.Limit(), "the limit grows up to the maximum")
+
+	var dones []func(bool, time.Duration)
+	for i := 0; i < l.Limit(); i++ {
+		done, err := l.Acquire(method, "")
+		require.NoError(t, err)
+		dones = append(dones, done)
+	}
+
+	_, err := l.Acquire(method, "")
+	assert.Error(t, err, "the limiter still rejects the calls over the limit")
+
+	for _, done := range dones {
+		done(true, 0)
+	}
+}
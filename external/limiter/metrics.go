@@ -0,0 +1,36 @@
+package limiter
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	inflightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "concurrency_limiter_inflight",
+		Help: "Current number of the in-flight calls by limiter name.",
+	}, []string{"name"})
+
+	limitGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "concurrency_limiter_limit",
+		Help: "Current concurrency limit by limiter name.",
+	}, []string{"name"})
+
+	rejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "concurrency_limiter_rejected_total",
+		Help: "Total number of the calls rejected by the concurrency limiter by limiter name and priority class.",
+	}, []string{"name", "class"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(
+			inflightGauge,
+			limitGauge,
+			rejectedTotal,
+		)
+	})
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	_ propagation.TextMapCarrier = MetadataCarrier{}
+	_ propagation.TextMapCarrier = (*FastHttpHeaderCarrier)(nil)
+)
+
+// MetadataCarrier adapts the grpc metadata to the propagation.TextMapCarrier interface.
+type MetadataCarrier metadata.MD
+
+func (c MetadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c MetadataCarrier) Keys() []string {
+	out := make([]string, 0, len(c))
+	for k := range c {
+		out = append(out, k)
+	}
+
+	return out
+}
+
+// FastHttpHeaderCarrier adapts the fasthttp request headers to the propagation.TextMapCarrier interface.
+type FastHttpHeaderCarrier struct {
+	*fasthttp.RequestHeader
+}
+
+func (c *FastHttpHeaderCarrier) Get(key string) string {
+	return string(c.Peek(key))
+}
+
+func (c *FastHttpHeaderCarrier) Set(key, value string) {
+	c.RequestHeader.Set(key, value)
+}
+
+func (c *FastHttpHeaderCarrier) Keys() (out []string) {
+	c.VisitAll(func(key, _ []byte) {
+		out = append(out, string(key))
+	})
+
+	return out
+}
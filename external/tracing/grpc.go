@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// StartGrpcClientSpan starts the client span of the call and injects its context into the outgoing metadata.
+func StartGrpcClientSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(RPCAttributes(fullMethod)...),
+	)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, MetadataCarrier(md))
+
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// StartGrpcServerSpan extracts the trace context from the incoming metadata and starts the server span of the call.
+func StartGrpcServerSpan(ctx context.Context, fullMethod string, peerAddr net.Addr) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, MetadataCarrier(md))
+	}
+
+	attrs := RPCAttributes(fullMethod)
+	if peerAddr != nil {
+		attrs = append(attrs, semconv.NetPeerIPKey.String(peerAddr.String()))
+	}
+
+	return Tracer().Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// EndGrpcSpan records the status of the finished call and ends the span.
+func EndGrpcSpan(span trace.Span, err error) {
+	defer span.End()
+
+	st, _ := status.FromError(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int64(int64(st.Code())))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, st.Message())
+	}
+}
+
+// RPCAttributes returns the semantic attributes of the grpc method in "/package.Service/Method" form.
+func RPCAttributes(fullMethod string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.RPCSystemKey.String("grpc")}
+
+	name := strings.TrimPrefix(fullMethod, "/")
+	if pos := strings.LastIndex(name, "/"); pos >= 0 {
+		attrs = append(attrs,
+			semconv.RPCServiceKey.String(name[:pos]),
+			semconv.RPCMethodKey.String(name[pos+1:]),
+		)
+	}
+
+	return attrs
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestGrpcSpanPropagation(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var cases = []struct {
+		name      string
+		ctx       context.Context
+		propagate bool
+	}{
+		{
+			name:      "propagated trace",
+			ctx:       metadata.AppendToOutgoingContext(context.Background(), "x-auth-cluster-id", "cluster"),
+			propagate: true,
+		},
+		{
+			name: "new trace",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clientCtx, clientSpan := StartGrpcClientSpan(c.ctx, "/services.GatewaySaverService/SendMetrics")
+			defer clientSpan.End()
+
+			md, _ := metadata.FromOutgoingContext(clientCtx)
+			assert.NotEmpty(t, md.Get("traceparent"))
+
+			if !c.propagate {
+				md = metadata.MD{}
+			}
+
+			_, serverSpan := StartGrpcServerSpan(metadata.NewIncomingContext(context.Background(), md),
+				"/services.GatewaySaverService/SendMetrics", nil)
+			defer serverSpan.End()
+
+			assert.Equal(t, c.propagate, serverSpan.SpanContext().TraceID() == clientSpan.SpanContext().TraceID())
+			assert.Equal(t, trace.SpanKindServer, serverSpan.(sdktrace.ReadOnlySpan).SpanKind())
+		})
+	}
+}
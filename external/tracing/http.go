@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	SpanKey = "tracingSpanKey"
+)
+
+// FastHttpMiddleware starts the server span for every request continuing the trace
+// propagated by the client, the span is kept in the request user values.
+func FastHttpMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		parent := otel.GetTextMapPropagator().Extract(context.Background(), &FastHttpHeaderCarrier{RequestHeader: &ctx.Request.Header})
+
+		method := string(ctx.Method())
+		spanCtx, span := Tracer().Start(parent, fmt.Sprintf("HTTP %s %s", method, ctx.Path()),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(method),
+				semconv.HTTPTargetKey.String(string(ctx.RequestURI())),
+				semconv.HTTPSchemeKey.String(string(ctx.URI().Scheme())),
+				semconv.HTTPHostKey.String(string(ctx.Host())),
+				semconv.HTTPUserAgentKey.String(string(ctx.UserAgent())),
+				semconv.NetPeerIPKey.String(ctx.RemoteIP().String()),
+			),
+		)
+		defer span.End()
+
+		ctx.SetUserValue(SpanKey, spanCtx)
+
+		next(ctx)
+
+		code := ctx.Response.StatusCode()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(code))
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(code, trace.SpanKindServer))
+	}
+}
+
+// ContextWithSpan returns the context carrying the server span started by FastHttpMiddleware,
+// it must be used as the parent for the spans created while handling the request.
+func ContextWithSpan(ctx *fasthttp.RequestCtx) context.Context {
+	if spanCtx, ok := ctx.UserValue(SpanKey).(context.Context); ok {
+		return spanCtx
+	}
+
+	return context.Background()
+}
+
+// StartClientSpan starts the client span of the outgoing request and injects its context into the request headers.
+func StartClientSpan(r *http.Request) (*http.Request, trace.Span) {
+	ctx, span := Tracer().Start(r.Context(), fmt.Sprintf("HTTP %s", r.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(r)...),
+	)
+
+	r = r.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	return r, span
+}
+
+// EndClientSpan records the result of the outgoing request and ends the span.
+func EndClientSpan(span trace.Span, resp *http.Response, err error) {
+	defer span.End()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(resp.StatusCode, trace.SpanKindClient))
+}
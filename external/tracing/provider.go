@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dysnix/predictkube-libs/external/configs"
+	"github.com/dysnix/predictkube-libs/external/enums"
+)
+
+const (
+	InstrumentationName = "github.com/dysnix/predictkube-libs"
+
+	DefaultCollectorEndpoint = "localhost:4317"
+)
+
+var (
+	_ configs.SignalCloserWithErr = (*Provider)(nil)
+)
+
+type Provider struct {
+	*sdktrace.TracerProvider
+	file io.Closer
+}
+
+// InitTracer creates the configured spans exporter and registers the global tracer provider
+// with W3C trace context and baggage propagation.
+func InitTracer(ctx context.Context, conf configs.Tracing) (out *Provider, err error) {
+	out = &Provider{}
+
+	var exporter sdktrace.SpanExporter
+
+	switch conf.Exporter {
+	case enums.OTLP:
+		endpoint := conf.Endpoint
+		if len(endpoint) == 0 {
+			endpoint = DefaultCollectorEndpoint
+		}
+
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+		if conf.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+
+		if exporter, err = otlptracegrpc.New(ctx, opts...); err != nil {
+			return nil, err
+		}
+	case enums.Stdout:
+		if exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout)); err != nil {
+			return nil, err
+		}
+	case enums.File:
+		if len(conf.FilePath) == 0 {
+			return nil, fmt.Errorf("tracing file exporter requires the file path")
+		}
+
+		file, err := os.OpenFile(conf.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		out.file = file
+
+		if exporter, err = stdouttrace.New(stdouttrace.WithWriter(file)); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", conf.Exporter)
+	}
+
+	out.TracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(newSampler(conf.SampleRatio)),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(conf.ServiceName),
+		)),
+	)
+
+	otel.SetTracerProvider(out.TracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return out, nil
+}
+
+// newSampler samples the share of the root traces by the ratio, 0 samples nothing and 1 samples everything,
+// the decision of the parent span is followed always.
+func newSampler(ratio float64) sdktrace.Sampler {
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+}
+
+// Close flushes the remaining spans and stops the exporter.
+func (p *Provider) Close() (err error) {
+	err = p.TracerProvider.Shutdown(context.Background())
+
+	if p.file != nil {
+		if errClose := p.file.Close(); err == nil {
+			err = errClose
+		}
+	}
+
+	return err
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
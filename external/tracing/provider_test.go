@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSampler(t *testing.T) {
+	parent := func(flags trace.TraceFlags) context.Context {
+		return trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: flags,
+			Remote:     true,
+		}))
+	}
+
+	var cases = []struct {
+		name    string
+		ratio   float64
+		ctx     context.Context
+		sampled bool
+	}{
+		{name: "zero ratio samples no roots", ratio: 0, ctx: context.Background()},
+		{name: "zero ratio follows the sampled parent", ratio: 0, ctx: parent(trace.FlagsSampled), sampled: true},
+		{name: "full ratio samples the roots", ratio: 1, ctx: context.Background(), sampled: true},
+		{name: "full ratio follows the unsampled parent", ratio: 1, ctx: parent(0)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(newSampler(c.ratio)))
+			defer func() {
+				_ = provider.Shutdown(context.Background())
+			}()
+
+			_, span := provider.Tracer(InstrumentationName).Start(c.ctx, "span")
+			defer span.End()
+
+			assert.Equal(t, c.sampled, span.SpanContext().IsSampled())
+		})
+	}
+}